@@ -0,0 +1,315 @@
+// Command merkle hashes files into Merkle trees and produces and checks
+// inclusion proofs for their chunks. It uses the same merkle package as our
+// Go services so shell scripts get byte for byte the same answers.
+//
+// Usage:
+//
+//	merkle root FILE [--chunk N] [--json]
+//	merkle proof FILE --leaf N [--chunk N] [--json]
+//	merkle verify --root R --leaf N --chunks N --proof P [--json] CHUNK
+//	merkle diff A B [--chunk N] [--json]
+//
+// The proof written by "merkle proof --json" is the format read by
+// "merkle verify". A proof or chunk named "-" is read from standard input.
+// The root, the index of the chunk and the number of chunks in the file
+// must come from somewhere the caller trusts; those in the proof are only
+// checked against them.
+//
+// The exit status is 0 on success, 1 when a proof does not verify or the
+// files differ, and 2 on any other error.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/asggo/structures/merkle"
+)
+
+// The default number of bytes in a chunk.
+const defaultChunk = 4096
+
+// errUsage is returned when the command line cannot be understood.
+var errUsage = errors.New("usage: merkle root|proof|verify|diff [arguments]")
+
+// Span is a run of consecutive changed chunks, [Start, End), along with the
+// byte range it covers.
+type Span struct {
+	Start  int   `json:"start"`
+	End    int   `json:"end"`
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+type rootOutput struct {
+	File   string `json:"file"`
+	Chunk  int    `json:"chunk"`
+	Blocks int    `json:"blocks"`
+	Root   string `json:"root"`
+}
+
+type proofOutput struct {
+	Root string `json:"root"`
+	merkle.Proof
+}
+
+type verifyOutput struct {
+	Index int  `json:"index"`
+	Valid bool `json:"valid"`
+}
+
+type diffOutput struct {
+	Chunk   int    `json:"chunk"`
+	Changed []Span `json:"changed"`
+}
+
+func main() {
+	status, err := run(os.Args[1:], os.Stdin, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "merkle:", err)
+	}
+
+	os.Exit(status)
+}
+
+// run executes the command line in args and returns the exit status.
+func run(args []string, stdin io.Reader, stdout io.Writer) (int, error) {
+	if len(args) == 0 {
+		return 2, errUsage
+	}
+
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+
+	chunk := fs.Int("chunk", defaultChunk, "number of bytes in a chunk")
+	asJSON := fs.Bool("json", false, "write JSON output")
+	leaf := fs.Int("leaf", -1, "index of the chunk to prove")
+	chunks := fs.Int("chunks", 0, "number of chunks in the file")
+	root := fs.String("root", "", "hex encoded root digest")
+	proof := fs.String("proof", "", "file holding a JSON proof")
+
+	files, err := parse(fs, args[1:])
+	if err != nil {
+		return 2, err
+	}
+
+	if *chunk <= 0 {
+		return 2, fmt.Errorf("invalid chunk size %d", *chunk)
+	}
+
+	var out interface{}
+	status := 0
+
+	switch args[0] {
+	case "root":
+		if len(files) != 1 {
+			return 2, errUsage
+		}
+
+		m, err := merkle.NewMerkleFromFile(files[0], *chunk)
+		if err != nil {
+			return 2, err
+		}
+
+		out = &rootOutput{File: files[0], Chunk: *chunk, Blocks: m.Blocks(), Root: m.Digest()}
+		if !*asJSON {
+			out = m.Digest()
+		}
+
+	case "proof":
+		if len(files) != 1 {
+			return 2, errUsage
+		}
+
+		m, err := merkle.NewMerkleFromFile(files[0], *chunk)
+		if err != nil {
+			return 2, err
+		}
+
+		p := m.Proof(*leaf)
+		if p == nil {
+			return 2, fmt.Errorf("leaf %d out of range, %s has %d chunks", *leaf, files[0], m.Blocks())
+		}
+
+		out = &proofOutput{Root: m.Digest(), Proof: *p}
+
+	case "verify":
+		// The root and the shape of the tree must come from somewhere the
+		// caller trusts, never from the proof itself.
+		if len(files) != 1 || *proof == "" || *root == "" || *leaf < 0 || *chunks <= 0 {
+			return 2, errUsage
+		}
+
+		var p proofOutput
+		data, err := readFile(*proof, stdin)
+		if err != nil {
+			return 2, err
+		}
+
+		if err := json.Unmarshal(data, &p); err != nil {
+			return 2, fmt.Errorf("%s: %v", *proof, err)
+		}
+
+		block, err := readFile(files[0], stdin)
+		if err != nil {
+			return 2, err
+		}
+
+		valid := p.VerifyAt(block, *leaf, *chunks, *root)
+		if !valid {
+			status = 1
+		}
+
+		out = &verifyOutput{Index: p.Index, Valid: valid}
+		if !*asJSON && valid {
+			out = "valid"
+		} else if !*asJSON {
+			out = "invalid"
+		}
+
+	case "diff":
+		if len(files) != 2 {
+			return 2, errUsage
+		}
+
+		spans, err := diff(files[0], files[1], *chunk)
+		if err != nil {
+			return 2, err
+		}
+
+		if len(spans) > 0 {
+			status = 1
+		}
+
+		out = &diffOutput{Chunk: *chunk, Changed: spans}
+		if !*asJSON {
+			var lines string
+			for _, s := range spans {
+				lines += fmt.Sprintf("chunks %d-%d bytes %d-%d\n", s.Start, s.End-1, s.Offset, s.Offset+s.Length-1)
+			}
+			out = lines
+		}
+
+	default:
+		return 2, errUsage
+	}
+
+	if s, ok := out.(string); ok {
+		if s != "" && s[len(s)-1] != '\n' {
+			s += "\n"
+		}
+		_, err = io.WriteString(stdout, s)
+	} else {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(out)
+	}
+
+	if err != nil {
+		return 2, err
+	}
+
+	return status, nil
+}
+
+// parse parses the flags in args, which may appear before, between or after
+// the positional arguments, and returns the positional arguments.
+func parse(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// readFile returns the contents of the named file, or of stdin if the name
+// is "-".
+func readFile(filename string, stdin io.Reader) ([]byte, error) {
+	if filename == "-" {
+		return ioutil.ReadAll(stdin)
+	}
+
+	return ioutil.ReadFile(filename)
+}
+
+// diff returns the spans of chunks that differ between files a and b.
+func diff(a, b string, chunk int) ([]Span, error) {
+	m1, err := merkle.NewMerkleFromFile(a, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	m2, err := merkle.NewMerkleFromFile(b, chunk)
+	if err != nil {
+		return nil, err
+	}
+
+	size, err := largest(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	return spans(m1.Changed(m2), chunk, size), nil
+}
+
+// largest returns the size of the larger of the two files.
+func largest(a, b string) (int64, error) {
+	var size int64
+
+	for _, filename := range []string{a, b} {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return 0, err
+		}
+
+		if info.Size() > size {
+			size = info.Size()
+		}
+	}
+
+	return size, nil
+}
+
+// spans groups the sorted chunk indices into runs of consecutive chunks. The
+// byte ranges are clipped to size.
+func spans(changed []int, chunk int, size int64) []Span {
+	var result []Span
+
+	for _, i := range changed {
+		n := len(result)
+		if n > 0 && result[n-1].End == i {
+			result[n-1].End++
+		} else {
+			result = append(result, Span{Start: i, End: i + 1})
+		}
+	}
+
+	for i := range result {
+		s := &result[i]
+		s.Offset = int64(s.Start) * int64(chunk)
+
+		end := int64(s.End) * int64(chunk)
+		if end > size {
+			end = size
+		}
+
+		s.Length = end - s.Offset
+	}
+
+	return result
+}
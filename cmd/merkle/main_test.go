@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/asggo/structures/merkle"
+)
+
+func TestRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "merkle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+
+	a := write("a", "aaaabbbbccccdddde")
+	b := write("b", "aaaaXbbbccccYYYYe")
+	chunk := write("chunk", "cccc")
+
+	var out bytes.Buffer
+
+	// root
+	status, err := run([]string{"root", a, "--chunk", "4"}, nil, &out)
+	if status != 0 || err != nil {
+		t.Fatal("root failed:", status, err)
+	}
+
+	expected := merkle.NewMerkle(merkle.Split([]byte("aaaabbbbccccdddde"), 4)).Digest()
+	if strings.TrimSpace(out.String()) != expected {
+		t.Errorf("Expected root %s, received %s.", expected, out.String())
+	}
+
+	// proof and verify
+	out.Reset()
+	status, err = run([]string{"proof", a, "--chunk", "4", "--leaf", "2"}, nil, &out)
+	if status != 0 || err != nil {
+		t.Fatal("proof failed:", status, err)
+	}
+
+	proof := write("proof", out.String())
+
+	out.Reset()
+	status, err = run([]string{"verify", "--root", expected, "--leaf", "2", "--chunks", "5", "--proof", proof, chunk}, nil, &out)
+	if status != 0 || err != nil || strings.TrimSpace(out.String()) != "valid" {
+		t.Error("Proof did not verify:", status, err, out.String())
+	}
+
+	out.Reset()
+	status, _ = run([]string{"verify", "--root", expected, "--leaf", "2", "--chunks", "5", "--proof", proof, a}, nil, &out)
+	if status != 1 {
+		t.Error("Expected status ", 1, "got", status)
+	}
+
+	// The chunk may come from standard input.
+	out.Reset()
+	status, _ = run([]string{"verify", "--root", expected, "--leaf", "2", "--chunks", "5", "--proof", proof, "-"}, strings.NewReader("cccc"), &out)
+	if status != 0 {
+		t.Error("Proof did not verify from standard input:", out.String())
+	}
+
+	// The root and position in the proof file are not trusted.
+	for _, args := range [][]string{
+		{"verify", "--leaf", "2", "--chunks", "5", "--proof", proof, chunk},
+		{"verify", "--root", expected, "--chunks", "5", "--proof", proof, chunk},
+		{"verify", "--root", expected, "--leaf", "2", "--proof", proof, chunk},
+	} {
+		out.Reset()
+		status, err = run(args, nil, &out)
+		if status != 2 || err != errUsage {
+			t.Error("Expected a usage error for", args, "got", status, err)
+		}
+	}
+
+	out.Reset()
+	status, _ = run([]string{"verify", "--root", expected, "--leaf", "3", "--chunks", "5", "--proof", proof, chunk}, nil, &out)
+	if status != 1 {
+		t.Error("Expected status ", 1, "got", status)
+	}
+
+	// diff
+	out.Reset()
+	status, err = run([]string{"diff", a, b, "--chunk", "4", "--json"}, nil, &out)
+	if status != 1 || err != nil {
+		t.Fatal("diff failed:", status, err)
+	}
+
+	var d diffOutput
+	if err := json.Unmarshal(out.Bytes(), &d); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(d.Changed) != 2 {
+		t.Fatal("Expected ", 2, "got", len(d.Changed))
+	}
+
+	if d.Changed[0] != (Span{Start: 1, End: 2, Offset: 4, Length: 4}) {
+		t.Error("Unexpected span", d.Changed[0])
+	}
+
+	if d.Changed[1] != (Span{Start: 3, End: 4, Offset: 12, Length: 4}) {
+		t.Error("Unexpected span", d.Changed[1])
+	}
+
+	if status, _ := run([]string{"diff", a, a}, nil, &out); status != 0 {
+		t.Error("Expected status ", 0, "got", status)
+	}
+
+	if status, _ := run([]string{"bogus"}, nil, &out); status != 2 {
+		t.Error("Expected status ", 2, "got", status)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	result := spans([]int{0, 1, 2, 5, 7, 8}, 10, 85)
+
+	expected := []Span{
+		{Start: 0, End: 3, Offset: 0, Length: 30},
+		{Start: 5, End: 6, Offset: 50, Length: 10},
+		{Start: 7, End: 9, Offset: 70, Length: 15},
+	}
+
+	if len(result) != len(expected) {
+		t.Fatal("Expected ", len(expected), "got", len(result))
+	}
+
+	for i := range result {
+		if result[i] != expected[i] {
+			t.Error("Expected ", expected[i], "got", result[i])
+		}
+	}
+}
+
+func TestVerifyInteriorNode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "merkle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	m := merkle.NewMerkle(merkle.Split([]byte("aaaabbbbccccdddd"), 4))
+
+	var leaves [][]byte
+	for _, l := range m.Leaves() {
+		leaf, _ := hex.DecodeString(l)
+		leaves = append(leaves, leaf)
+	}
+
+	// The two leaf digests under the left child of the root, claimed as the
+	// first chunk of a file of two chunks.
+	uncle := sha256.Sum256(append(append([]byte(nil), leaves[2]...), leaves[3]...))
+	forged, _ := json.Marshal(&merkle.Proof{Index: 0, Size: 2, Hashes: []string{hex.EncodeToString(uncle[:])}})
+
+	proof := filepath.Join(dir, "proof")
+	chunk := filepath.Join(dir, "chunk")
+	if err := ioutil.WriteFile(proof, forged, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(chunk, append(append([]byte(nil), leaves[0]...), leaves[1]...), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	status, err := run([]string{"verify", "--root", m.Digest(), "--leaf", "0", "--chunks", "4", "--proof", proof, chunk}, nil, &out)
+	if status != 1 || err != nil || strings.TrimSpace(out.String()) != "invalid" {
+		t.Error("Forged proof verified:", status, err, out.String())
+	}
+}
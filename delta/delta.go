@@ -0,0 +1,145 @@
+// The delta package computes and applies rsync style deltas between files
+// and directories. Files are split into fixed size chunks and hashed into
+// Merkle trees. Files whose roots match are skipped, and every chunk of the
+// source is matched by digest against all chunks of the destination, so
+// chunks that moved are copied rather than sent. Chunks are matched only on
+// chunk boundaries, so data inserted part way through a file causes the
+// chunks after it to be sent.
+package delta
+
+import (
+	"fmt"
+
+	"github.com/asggo/structures/merkle"
+)
+
+// The default number of bytes in a chunk.
+const DefaultChunk = 4096
+
+// The kinds of operation in a delta.
+const (
+	// OpCopy copies Length bytes from Offset of the destination file.
+	OpCopy = iota
+
+	// OpData writes the bytes held in Data.
+	OpData
+)
+
+// Op is a single step in rebuilding a source file from a destination file.
+type Op struct {
+	Kind   int
+	Offset int64
+	Length int64
+	Data   []byte
+}
+
+// Diff returns the operations that rebuild src from dst.
+func Diff(src, dst []byte, chunk int) []Op {
+	return newFile(src, chunk).diff(newFile(dst, chunk), chunk)
+}
+
+// file is the content of a file split into chunks and its Merkle tree.
+type file struct {
+	blocks [][]byte
+	tree   *merkle.Merkle
+}
+
+func newFile(data []byte, chunk int) *file {
+	f := new(file)
+	f.blocks = merkle.Split(data, chunk)
+	f.tree = merkle.NewMerkle(f.blocks)
+
+	return f
+}
+
+// diff returns the operations that rebuild src from dst.
+func (src *file) diff(dst *file, chunk int) []Op {
+	// Index the destination chunks by digest, keeping the first of any
+	// repeated chunk.
+	dstLeaves := dst.tree.Leaves()
+	offsets := make(map[string]int64)
+
+	for i := len(dstLeaves) - 1; i >= 0; i-- {
+		offsets[dstLeaves[i]] = int64(i) * int64(chunk)
+	}
+
+	// Chunks that did not change are copied from the same position, so only
+	// the changed ones need to be looked up.
+	changed := make(map[int]bool)
+	for _, i := range src.tree.Changed(dst.tree) {
+		changed[i] = true
+	}
+
+	srcLeaves := src.tree.Leaves()
+	var ops []Op
+
+	for i, block := range src.blocks {
+		if len(block) == 0 {
+			continue
+		}
+
+		offset, ok := int64(i)*int64(chunk), true
+		if changed[i] {
+			offset, ok = offsets[srcLeaves[i]]
+		}
+
+		if ok {
+			ops = appendCopy(ops, offset, int64(len(block)))
+		} else {
+			ops = appendData(ops, block)
+		}
+	}
+
+	return ops
+}
+
+// appendCopy adds a copy to ops, extending the last copy if the two are
+// contiguous.
+func appendCopy(ops []Op, offset, length int64) []Op {
+	if n := len(ops); n > 0 && ops[n-1].Kind == OpCopy && ops[n-1].Offset+ops[n-1].Length == offset {
+		ops[n-1].Length += length
+		return ops
+	}
+
+	return append(ops, Op{Kind: OpCopy, Offset: offset, Length: length})
+}
+
+// appendData adds literal data to ops, extending the last data operation if
+// there is one.
+func appendData(ops []Op, data []byte) []Op {
+	if n := len(ops); n > 0 && ops[n-1].Kind == OpData {
+		ops[n-1].Data = append(ops[n-1].Data, data...)
+		ops[n-1].Length += int64(len(data))
+		return ops
+	}
+
+	buf := make([]byte, len(data))
+	copy(buf, data)
+
+	return append(ops, Op{Kind: OpData, Length: int64(len(data)), Data: buf})
+}
+
+// Apply rebuilds the source from dst using the operations returned by Diff.
+func Apply(dst []byte, ops []Op) ([]byte, error) {
+	var out []byte
+
+	for _, op := range ops {
+		switch op.Kind {
+		case OpCopy:
+			// Compare without adding, which could overflow.
+			if op.Offset < 0 || op.Length < 0 || op.Offset > int64(len(dst)) || op.Length > int64(len(dst))-op.Offset {
+				return nil, fmt.Errorf("delta: copy of %d bytes at %d is outside the destination", op.Length, op.Offset)
+			}
+
+			out = append(out, dst[op.Offset:op.Offset+op.Length]...)
+
+		case OpData:
+			out = append(out, op.Data...)
+
+		default:
+			return nil, fmt.Errorf("delta: unknown operation %d", op.Kind)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,59 @@
+package delta
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dst := []byte("aaaabbbbccccddddee")
+
+	// Move chunks around, change one and add a tail.
+	src := []byte("ccccaaaaXXXXbbbbddddeeff")
+
+	ops := Diff(src, dst, 4)
+
+	out, err := Apply(dst, ops)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(out, src) {
+		t.Fatalf("Expected %s, received %s.", src, out)
+	}
+
+	// Only the changed chunk and the tail are sent.
+	var sent int
+	for _, op := range ops {
+		if op.Kind == OpData {
+			sent += len(op.Data)
+		}
+	}
+
+	if sent != 8 {
+		t.Error("Expected ", 8, "bytes sent got", sent)
+	}
+
+	// Identical files copy everything in one operation.
+	ops = Diff(dst, dst, 4)
+	if len(ops) != 1 || ops[0].Kind != OpCopy || ops[0].Length != int64(len(dst)) {
+		t.Error("Unexpected operations for identical files", ops)
+	}
+
+	// Rebuilding from nothing and rebuilding nothing.
+	for _, pair := range [][2][]byte{{src, nil}, {nil, dst}} {
+		out, err := Apply(pair[1], Diff(pair[0], pair[1], 4))
+		if err != nil || !bytes.Equal(out, pair[0]) {
+			t.Errorf("Expected %q, received %q.", pair[0], out)
+		}
+	}
+
+	if _, err := Apply(dst, []Op{{Kind: OpCopy, Offset: 16, Length: 4}}); err == nil {
+		t.Error("Expected an error for a copy past the end.")
+	}
+
+	if _, err := Apply(dst, []Op{{Kind: OpCopy, Offset: 1, Length: math.MaxInt64}}); err == nil {
+		t.Error("Expected an error for a copy whose end overflows.")
+	}
+}
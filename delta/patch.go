@@ -0,0 +1,418 @@
+package delta
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// The first bytes of every patch stream.
+const magic = "MDELTA2\n"
+
+// The record types in a patch stream.
+const (
+	recordFile   = 'F'
+	recordRemove = 'R'
+	recordEnd    = 'E'
+)
+
+// The longest path accepted in a patch stream.
+const maxPath = 4096
+
+// The longest Merkle root accepted in a patch stream.
+const maxRoot = 128
+
+// ErrFormat is returned when a patch stream is malformed.
+var ErrFormat = errors.New("delta: malformed patch")
+
+// ErrMismatch is returned when a rebuilt file does not match the Merkle root
+// of its source.
+var ErrMismatch = errors.New("delta: rebuilt file does not match its source")
+
+// FileDelta holds the operations that rebuild one source file from the
+// destination file at the same path, which may not exist. Root is the hex
+// encoded Merkle root of the source file, which the rebuilt file must match.
+type FileDelta struct {
+	Path string
+	Mode os.FileMode
+	Root string
+	Ops  []Op
+}
+
+// Patch holds the changes that make a destination directory match a source
+// directory. Paths are relative and slash separated.
+type Patch struct {
+	Chunk  int
+	Files  []*FileDelta
+	Remove []string
+}
+
+// DiffDir returns the patch that turns the dst directory into a copy of the
+// src directory. Only regular files are compared; files whose Merkle roots
+// and permissions match are left out of the patch.
+func DiffDir(src, dst string, chunk int) (*Patch, error) {
+	p := &Patch{Chunk: chunk}
+
+	srcFiles, err := regularFiles(src)
+	if err != nil {
+		return nil, err
+	}
+
+	dstFiles, err := regularFiles(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range sortedKeys(srcFiles) {
+		srcData, err := ioutil.ReadFile(filepath.Join(src, filepath.FromSlash(path)))
+		if err != nil {
+			return nil, err
+		}
+
+		var dstData []byte
+		if _, ok := dstFiles[path]; ok {
+			dstData, err = ioutil.ReadFile(filepath.Join(dst, filepath.FromSlash(path)))
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		srcFile := newFile(srcData, chunk)
+		dstFile := newFile(dstData, chunk)
+		mode := srcFiles[path]
+
+		if dstMode, ok := dstFiles[path]; ok && dstMode == mode && srcFile.tree.Equal(dstFile.tree) {
+			continue
+		}
+
+		p.Files = append(p.Files, &FileDelta{Path: path, Mode: mode, Root: srcFile.tree.Digest(), Ops: srcFile.diff(dstFile, chunk)})
+	}
+
+	for _, path := range sortedKeys(dstFiles) {
+		if _, ok := srcFiles[path]; !ok {
+			p.Remove = append(p.Remove, path)
+		}
+	}
+
+	return p, nil
+}
+
+// regularFiles returns the permissions of the regular files below dir, keyed
+// by slash separated relative path.
+func regularFiles(dir string) (map[string]os.FileMode, error) {
+	files := make(map[string]os.FileMode)
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+
+		files[filepath.ToSlash(rel)] = info.Mode().Perm()
+
+		return nil
+	})
+
+	return files, err
+}
+
+func sortedKeys(m map[string]os.FileMode) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Apply changes the dir directory to match the source the patch was made
+// from. Each file is rebuilt, checked against the Merkle root of its source,
+// written to a temporary file and renamed into place. A patch applied to a
+// different destination than the one it was made from fails the check
+// rather than writing the wrong data.
+func (p *Patch) Apply(dir string) error {
+	for _, f := range p.Files {
+		path, err := localPath(dir, f.Path)
+		if err != nil {
+			return err
+		}
+
+		old, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		data, err := Apply(old, f.Ops)
+		if err != nil {
+			return fmt.Errorf("%s: %v", f.Path, err)
+		}
+
+		if newFile(data, p.Chunk).tree.Digest() != f.Root {
+			return fmt.Errorf("%s: %v", f.Path, ErrMismatch)
+		}
+
+		if err := writeFile(path, data, f.Mode); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range p.Remove {
+		path, err := localPath(dir, name)
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// localPath returns the path of name below dir. Names that would escape dir
+// are rejected, as are names that pass through a symbolic link below dir,
+// since the link could lead anywhere.
+func localPath(dir, name string) (string, error) {
+	native := filepath.Clean(filepath.FromSlash(name))
+	if !filepath.IsLocal(native) {
+		return "", fmt.Errorf("delta: path %q is outside the directory", name)
+	}
+
+	path := dir
+	for _, part := range strings.Split(native, string(filepath.Separator)) {
+		path = filepath.Join(path, part)
+
+		info, err := os.Lstat(path)
+		if os.IsNotExist(err) {
+			break
+		}
+
+		if err != nil {
+			return "", err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("delta: path %q passes through a symbolic link", name)
+		}
+	}
+
+	return filepath.Join(dir, native), nil
+}
+
+// writeFile replaces the named file with data.
+func writeFile(path string, data []byte, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".delta")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// Write serializes the patch to w as a patch stream.
+func (p *Patch) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	bw.WriteString(magic)
+	writeUvarint(bw, uint64(p.Chunk))
+
+	for _, f := range p.Files {
+		bw.WriteByte(recordFile)
+		writeBytes(bw, []byte(f.Path))
+		writeUvarint(bw, uint64(f.Mode))
+		writeBytes(bw, []byte(f.Root))
+		writeUvarint(bw, uint64(len(f.Ops)))
+
+		for _, op := range f.Ops {
+			bw.WriteByte(byte(op.Kind))
+
+			if op.Kind == OpCopy {
+				writeUvarint(bw, uint64(op.Offset))
+				writeUvarint(bw, uint64(op.Length))
+			} else {
+				writeBytes(bw, op.Data)
+			}
+		}
+	}
+
+	for _, path := range p.Remove {
+		bw.WriteByte(recordRemove)
+		writeBytes(bw, []byte(path))
+	}
+
+	bw.WriteByte(recordEnd)
+
+	return bw.Flush()
+}
+
+func writeUvarint(w *bufio.Writer, v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	w.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func writeBytes(w *bufio.Writer, b []byte) {
+	writeUvarint(w, uint64(len(b)))
+	w.Write(b)
+}
+
+// ReadPatch reads a patch stream written by Patch.Write.
+func ReadPatch(r io.Reader) (*Patch, error) {
+	br := bufio.NewReader(r)
+
+	head := make([]byte, len(magic))
+	if _, err := io.ReadFull(br, head); err != nil || string(head) != magic {
+		return nil, ErrFormat
+	}
+
+	chunk, err := binary.ReadUvarint(br)
+	if err != nil {
+		return nil, ErrFormat
+	}
+
+	p := &Patch{Chunk: int(chunk)}
+
+	for {
+		kind, err := br.ReadByte()
+		if err != nil {
+			return nil, ErrFormat
+		}
+
+		switch kind {
+		case recordEnd:
+			return p, nil
+
+		case recordRemove:
+			path, err := readBytes(br, maxPath)
+			if err != nil {
+				return nil, err
+			}
+
+			p.Remove = append(p.Remove, string(path))
+
+		case recordFile:
+			f, err := readFile(br)
+			if err != nil {
+				return nil, err
+			}
+
+			p.Files = append(p.Files, f)
+
+		default:
+			return nil, ErrFormat
+		}
+	}
+}
+
+func readFile(r *bufio.Reader) (*FileDelta, error) {
+	path, err := readBytes(r, maxPath)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, ErrFormat
+	}
+
+	root, err := readBytes(r, maxRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, ErrFormat
+	}
+
+	f := &FileDelta{Path: string(path), Mode: os.FileMode(mode).Perm(), Root: string(root)}
+
+	for i := uint64(0); i < count; i++ {
+		kind, err := r.ReadByte()
+		if err != nil {
+			return nil, ErrFormat
+		}
+
+		op := Op{Kind: int(kind)}
+
+		switch op.Kind {
+		case OpCopy:
+			offset, err1 := binary.ReadUvarint(r)
+			length, err2 := binary.ReadUvarint(r)
+			if err1 != nil || err2 != nil {
+				return nil, ErrFormat
+			}
+
+			op.Offset, op.Length = int64(offset), int64(length)
+
+		case OpData:
+			op.Data, err = readBytes(r, -1)
+			if err != nil {
+				return nil, err
+			}
+
+			op.Length = int64(len(op.Data))
+
+		default:
+			return nil, ErrFormat
+		}
+
+		f.Ops = append(f.Ops, op)
+	}
+
+	return f, nil
+}
+
+// readBytes reads a length prefixed byte slice of at most max bytes, or of
+// any length if max is negative.
+func readBytes(r *bufio.Reader, max int) ([]byte, error) {
+	size, err := binary.ReadUvarint(r)
+	if err != nil || (max >= 0 && size > uint64(max)) {
+		return nil, ErrFormat
+	}
+
+	// Copy rather than allocate up front so a bogus length cannot exhaust
+	// memory before the stream runs out.
+	var buf bytes.Buffer
+	if n, err := io.CopyN(&buf, r, int64(size)); err != nil || uint64(n) != size {
+		return nil, ErrFormat
+	}
+
+	return buf.Bytes(), nil
+}
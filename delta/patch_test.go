@@ -0,0 +1,179 @@
+package delta
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTree(t *testing.T, dir string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPatch(t *testing.T) {
+	root, err := ioutil.TempDir("", "delta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	src := filepath.Join(root, "src")
+	dst := filepath.Join(root, "dst")
+
+	var lines []string
+	for i := 0; i < 2000; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	big := strings.Join(lines, "\n")[:16384]
+
+	writeTree(t, src, map[string]string{
+		"same.txt":     "unchanged",
+		"moved.txt":    big[8192:] + big[:8192],
+		"new/file.txt": "brand new",
+	})
+
+	writeTree(t, dst, map[string]string{
+		"same.txt":  "unchanged",
+		"moved.txt": big,
+		"gone.txt":  "removed",
+	})
+
+	p, err := DiffDir(src, dst, DefaultChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.Files) != 2 || len(p.Remove) != 1 || p.Remove[0] != "gone.txt" {
+		t.Fatal("Unexpected patch", p.Files, p.Remove)
+	}
+
+	// The moved halves are copied, not sent.
+	for _, op := range p.Files[0].Ops {
+		if op.Kind == OpData {
+			t.Error("Moved chunks were sent.")
+		}
+	}
+
+	// Round trip the patch through a stream and apply it.
+	var stream bytes.Buffer
+	if err := p.Write(&stream); err != nil {
+		t.Fatal(err)
+	}
+
+	p2, err := ReadPatch(&stream)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p2.Apply(dst); err != nil {
+		t.Fatal(err)
+	}
+
+	// The directories now match.
+	p3, err := DiffDir(src, dst, DefaultChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p3.Files) != 0 || len(p3.Remove) != 0 {
+		t.Error("Directories differ after applying the patch", p3.Files, p3.Remove)
+	}
+
+	// Malformed streams and escaping paths are rejected.
+	if _, err := ReadPatch(strings.NewReader("MDELTA2\n\x04F\xff\xff\xff\xff\x0f")); err != ErrFormat {
+		t.Error("Expected ", ErrFormat, "got", err)
+	}
+
+	evil := &Patch{Files: []*FileDelta{{Path: "../evil", Mode: 0644}}}
+	if err := evil.Apply(dst); err == nil {
+		t.Error("Expected an error for a path outside the directory.")
+	}
+}
+
+func TestPatchMismatch(t *testing.T) {
+	root, err := ioutil.TempDir("", "delta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	src := filepath.Join(root, "src")
+	dst := filepath.Join(root, "dst")
+	other := filepath.Join(root, "other")
+
+	writeTree(t, src, map[string]string{"file.txt": "aaaabbbbcccc"})
+	writeTree(t, dst, map[string]string{"file.txt": "aaaaXXXXcccc"})
+	writeTree(t, other, map[string]string{"file.txt": "zzzzYYYYwwww"})
+
+	p, err := DiffDir(src, dst, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The patch copies chunks that only dst holds, so applied to another
+	// directory it rebuilds the wrong file, which is caught before it is
+	// written.
+	if err := p.Apply(other); err == nil || !strings.Contains(err.Error(), ErrMismatch.Error()) {
+		t.Error("Expected ", ErrMismatch, "got", err)
+	}
+
+	if data, _ := ioutil.ReadFile(filepath.Join(other, "file.txt")); string(data) != "zzzzYYYYwwww" {
+		t.Error("File was changed by a mismatched patch:", string(data))
+	}
+
+	if err := p.Apply(dst); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPatchSymlink(t *testing.T) {
+	root, err := ioutil.TempDir("", "delta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	dst := filepath.Join(root, "dst")
+	outside := filepath.Join(root, "outside")
+	writeTree(t, dst, map[string]string{"keep.txt": "keep"})
+	writeTree(t, outside, map[string]string{"victim.txt": "victim"})
+
+	if err := os.Symlink(outside, filepath.Join(dst, "link")); err != nil {
+		t.Skip("Symbolic links are not supported:", err)
+	}
+
+	data := []byte("written through a link")
+	write := &Patch{Files: []*FileDelta{{
+		Path: "link/victim.txt",
+		Mode: 0644,
+		Root: newFile(data, DefaultChunk).tree.Digest(),
+		Ops:  []Op{{Kind: OpData, Length: int64(len(data)), Data: data}},
+	}}}
+
+	if err := write.Apply(dst); err == nil {
+		t.Error("Expected an error for a write through a symbolic link.")
+	}
+
+	remove := &Patch{Remove: []string{"link/victim.txt"}}
+	if err := remove.Apply(dst); err == nil {
+		t.Error("Expected an error for a removal through a symbolic link.")
+	}
+
+	if data, err := ioutil.ReadFile(filepath.Join(outside, "victim.txt")); err != nil || string(data) != "victim" {
+		t.Error("File outside the directory was changed:", string(data), err)
+	}
+}
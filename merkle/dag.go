@@ -0,0 +1,275 @@
+package merkle
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// Hash identifies a hash algorithm by its multihash code.
+type Hash uint64
+
+// The hash algorithms supported in content identifiers.
+const (
+	SHA1   Hash = 0x11
+	SHA256 Hash = 0x12
+	SHA512 Hash = 0x13
+)
+
+// Sum returns the digest of data using the hash algorithm. It returns nil if
+// the algorithm is not supported.
+func (h Hash) Sum(data []byte) []byte {
+	switch h {
+	case SHA1:
+		digest := sha1.Sum(data)
+		return digest[:]
+	case SHA256:
+		digest := sha256.Sum256(data)
+		return digest[:]
+	case SHA512:
+		digest := sha512.Sum512(data)
+		return digest[:]
+	}
+
+	return nil
+}
+
+// ID is a self-describing content identifier. It holds a multihash: the
+// varint hash code, the varint digest length and the digest itself. IDs are
+// comparable and may be used as map keys.
+type ID string
+
+// NewID returns the ID of data hashed with the given algorithm.
+func NewID(h Hash, data []byte) ID {
+	digest := h.Sum(data)
+
+	buf := make([]byte, 0, 2*binary.MaxVarintLen64+len(digest))
+	buf = binary.AppendUvarint(buf, uint64(h))
+	buf = binary.AppendUvarint(buf, uint64(len(digest)))
+	buf = append(buf, digest...)
+
+	return ID(buf)
+}
+
+// ParseID returns the ID represented by the hex encoded string s.
+func ParseID(s string) (ID, error) {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+
+	id := ID(buf)
+	if _, digest := id.split(); digest == nil {
+		return "", fmt.Errorf("merkle: invalid id %q", s)
+	}
+
+	return id, nil
+}
+
+// Hash returns the hash algorithm of the ID.
+func (id ID) Hash() Hash {
+	h, _ := id.split()
+
+	return h
+}
+
+// Digest returns the digest held by the ID.
+func (id ID) Digest() []byte {
+	_, digest := id.split()
+
+	return digest
+}
+
+// String returns the hex encoded ID.
+func (id ID) String() string {
+	return hex.EncodeToString([]byte(id))
+}
+
+// split decodes the ID into its parts. The digest is nil if the ID is
+// malformed.
+func (id ID) split() (Hash, []byte) {
+	buf := []byte(id)
+
+	code, n := binary.Uvarint(buf)
+	if n <= 0 {
+		return 0, nil
+	}
+	buf = buf[n:]
+
+	size, n := binary.Uvarint(buf)
+	if n <= 0 || uint64(len(buf)-n) != size {
+		return 0, nil
+	}
+
+	return Hash(code), buf[n:]
+}
+
+// DAGNode is a node of a Merkle DAG. It may carry a payload and link to any
+// number of children by their IDs.
+type DAGNode struct {
+	Payload []byte
+	Links   []ID
+}
+
+// encode returns the canonical encoding of the node that its ID is
+// computed over: the number of links, each link prefixed with its length,
+// then the payload prefixed with its length.
+func (n *DAGNode) encode() []byte {
+	var buf []byte
+
+	buf = binary.AppendUvarint(buf, uint64(len(n.Links)))
+	for _, link := range n.Links {
+		buf = binary.AppendUvarint(buf, uint64(len(link)))
+		buf = append(buf, link...)
+	}
+
+	buf = binary.AppendUvarint(buf, uint64(len(n.Payload)))
+	buf = append(buf, n.Payload...)
+
+	return buf
+}
+
+// ID returns the ID of the node using the given hash algorithm.
+func (n *DAGNode) ID(h Hash) ID {
+	return NewID(h, n.encode())
+}
+
+// Verify returns true if id identifies this node.
+func (n *DAGNode) Verify(id ID) bool {
+	if id.Hash().Sum(nil) == nil {
+		return false
+	}
+
+	return n.ID(id.Hash()) == id
+}
+
+// DAG is a content-addressed store of Merkle DAG nodes. Unlike the binary
+// Merkle type a node may have any number of children, which keeps trees
+// over many blocks shallow.
+type DAG struct {
+	hash  Hash
+	nodes map[ID]*DAGNode
+}
+
+// Len returns the number of nodes in the DAG.
+func (d *DAG) Len() int {
+	return len(d.nodes)
+}
+
+// Add stores a node with the given payload and links and returns its ID.
+// The payload and links are copied. Adding a node that is already stored has
+// no effect.
+func (d *DAG) Add(payload []byte, links []ID) ID {
+	n := &DAGNode{Payload: append([]byte(nil), payload...), Links: append([]ID(nil), links...)}
+	id := n.ID(d.hash)
+
+	if _, ok := d.nodes[id]; !ok {
+		d.nodes[id] = n
+	}
+
+	return id
+}
+
+// Get returns the node with the given ID, or nil if it is not stored.
+func (d *DAG) Get(id ID) *DAGNode {
+	return d.nodes[id]
+}
+
+// Build stores a balanced tree over the blocks in which every interior node
+// links to at most fanout children, and returns the ID of the root. Each
+// block becomes the payload of a leaf node and the root is always an
+// interior node, so even a single empty block is told apart from no blocks.
+// Fanouts below two are treated as two.
+func (d *DAG) Build(blocks [][]byte, fanout int) ID {
+	if fanout < 2 {
+		fanout = 2
+	}
+
+	var level []ID
+
+	for i := range blocks {
+		level = append(level, d.Add(blocks[i], nil))
+	}
+
+	if len(level) == 0 {
+		return d.Add(nil, nil)
+	}
+
+	// Build parent levels until there is only one parent, and at least one
+	// level above the leaves.
+	for first := true; first || len(level) > 1; first = false {
+		var parents []ID
+
+		for i := 0; i < len(level); i += fanout {
+			end := i + fanout
+			if end > len(level) {
+				end = len(level)
+			}
+
+			parents = append(parents, d.Add(nil, level[i:end]))
+		}
+
+		level = parents
+	}
+
+	return level[0]
+}
+
+// Payloads walks the DAG depth first from the given root and returns the
+// payloads of its nodes in order, a node's payload before its children's.
+// Leaves always have their payload returned, even when it is empty, so the
+// payloads of a tree from Build are its blocks. Interior nodes only have
+// non-empty payloads returned. A root with neither payload nor links is the
+// empty DAG and has no payloads.
+// Every node is checked against its ID. It returns false if a node is
+// missing or does not match its ID.
+func (d *DAG) Payloads(root ID) ([][]byte, bool) {
+	var payloads [][]byte
+
+	if n := d.nodes[root]; n != nil && len(n.Payload) == 0 && len(n.Links) == 0 && n.Verify(root) {
+		return nil, true
+	}
+
+	if !d.walk(root, &payloads) {
+		return nil, false
+	}
+
+	return payloads, true
+}
+
+func (d *DAG) walk(id ID, payloads *[][]byte) bool {
+	n := d.nodes[id]
+	if n == nil || !n.Verify(id) {
+		return false
+	}
+
+	if len(n.Payload) > 0 || len(n.Links) == 0 {
+		*payloads = append(*payloads, n.Payload)
+	}
+
+	for _, link := range n.Links {
+		if !d.walk(link, payloads) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewDAG returns an empty DAG whose node IDs use the given hash algorithm.
+// Unsupported algorithms fall back to SHA256.
+func NewDAG(h Hash) *DAG {
+	d := new(DAG)
+
+	if h.Sum(nil) == nil {
+		h = SHA256
+	}
+
+	d.hash = h
+	d.nodes = make(map[ID]*DAGNode)
+
+	return d
+}
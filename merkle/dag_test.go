@@ -0,0 +1,149 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"testing"
+)
+
+func TestID(t *testing.T) {
+	id := NewID(SHA256, []byte("aaaaa"))
+	digest := sha256.Sum256([]byte("aaaaa"))
+
+	if id.Hash() != SHA256 || !bytes.Equal(id.Digest(), digest[:]) {
+		t.Error("ID does not hold the hash algorithm and digest.")
+	}
+
+	// The binary form is a multihash: 0x12, 0x20 and the digest.
+	if id.String() != "1220"+encode(digest[:]) {
+		t.Errorf("Expected %s, received %s.", "1220"+encode(digest[:]), id.String())
+	}
+
+	parsed, err := ParseID(id.String())
+	if err != nil || parsed != id {
+		t.Error("ID did not survive parsing.", err)
+	}
+
+	if _, err := ParseID("1220abcd"); err == nil {
+		t.Error("Expected an error for a truncated ID.")
+	}
+
+	if len(NewID(SHA512, nil).Digest()) != 64 || len(NewID(SHA1, nil).Digest()) != 20 {
+		t.Error("Digest length does not match the hash algorithm.")
+	}
+}
+
+func TestDAG(t *testing.T) {
+	blocks := testBlocks(1000)
+
+	for _, fanout := range []int{2, 3, 174} {
+		d := NewDAG(SHA256)
+		root := d.Build(blocks, fanout)
+
+		payloads, ok := d.Payloads(root)
+		if !ok {
+			t.Fatal("Could not walk the DAG.")
+		}
+
+		if len(payloads) != len(blocks) {
+			t.Fatal("Expected ", len(blocks), "got", len(payloads))
+		}
+
+		for i := range blocks {
+			if !bytes.Equal(payloads[i], blocks[i]) {
+				t.Fatalf("Block %d was not returned in order.", i)
+			}
+		}
+
+		// No interior node may link to more than fanout children.
+		for _, n := range d.nodes {
+			if len(n.Links) > fanout {
+				t.Error("Node has ", len(n.Links), "links with fanout", fanout)
+			}
+		}
+	}
+
+	// With a fanout of 174 the 1000 blocks need one level of 6 nodes below
+	// the root.
+	d := NewDAG(SHA256)
+	root := d.Build(blocks, 174)
+
+	if len(d.Get(root).Links) != 6 || d.Len() != 1007 {
+		t.Error("Unexpected shape, root has", len(d.Get(root).Links), "links and", d.Len(), "nodes")
+	}
+
+	// The same content always gets the same ID and is stored once.
+	if d.Build(blocks, 174) != root || d.Len() != 1007 {
+		t.Error("Rebuilding the same content changed the DAG.")
+	}
+
+	if NewDAG(SHA512).Build(blocks, 174) == root {
+		t.Error("IDs with different hash algorithms should differ.")
+	}
+
+	// Nodes may carry a payload and links.
+	leaf := d.Add([]byte("leaf"), nil)
+	parent := d.Add([]byte("parent"), []ID{leaf, leaf})
+
+	payloads, _ := d.Payloads(parent)
+	if len(payloads) != 3 || string(payloads[0]) != "parent" {
+		t.Error("Payloads were not returned depth first.")
+	}
+
+	// A tampered node no longer matches its ID.
+	d.Get(leaf).Payload = []byte("evil")
+	if _, ok := d.Payloads(parent); ok {
+		t.Error("Tampered node was accepted.")
+	}
+
+	if _, ok := d.Payloads(NewID(SHA256, nil)); ok {
+		t.Error("Missing node was accepted.")
+	}
+
+	// The caller's slices are copied when the node is added.
+	payload := []byte("copied")
+	links := []ID{leaf}
+	copied := d.Add(payload, links)
+	payload[0], links[0] = 'C', parent
+
+	if n := d.Get(copied); string(n.Payload) != "copied" || n.Links[0] != leaf || !n.Verify(copied) {
+		t.Error("Changing the caller's slices changed the stored node.")
+	}
+
+	if (&DAGNode{}).Verify(NewID(Hash(0x99), nil)) {
+		t.Error("Node verified with an unsupported hash algorithm.")
+	}
+}
+
+func TestDAGEmptyBlocks(t *testing.T) {
+	cases := [][][]byte{
+		{},
+		{{}},
+		{{}, {}},
+		{[]byte("a"), {}, []byte("b")},
+		{{}, []byte("a")},
+	}
+
+	roots := make(map[ID]bool)
+
+	for _, blocks := range cases {
+		d := NewDAG(SHA256)
+		root := d.Build(blocks, 2)
+
+		payloads, ok := d.Payloads(root)
+		if !ok || len(payloads) != len(blocks) {
+			t.Fatal("Expected ", len(blocks), "got", len(payloads))
+		}
+
+		for i := range blocks {
+			if !bytes.Equal(payloads[i], blocks[i]) {
+				t.Errorf("Block %d was not returned in order.", i)
+			}
+		}
+
+		if roots[root] {
+			t.Error("Different blocks have the same root", root)
+		}
+		roots[root] = true
+	}
+}
@@ -0,0 +1,212 @@
+package merkle
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// MaxShares is the largest number of shares a list of blocks can be extended
+// to. Each share is the evaluation of a polynomial at a distinct element of
+// GF(2^8).
+const MaxShares = 256
+
+// Extended is a list of blocks extended with Reed-Solomon parity shares and
+// committed to with a Merkle tree over all of the shares. Any Data() of the
+// Total() shares are enough to rebuild the blocks, so a light client that
+// samples random shares and checks their proofs gains confidence that the
+// blocks are available without downloading them.
+type Extended struct {
+	data   int
+	shares [][]byte
+	tree   *Merkle
+}
+
+// Digest returns the hex encoded digest of the Merkle tree over the shares.
+func (e *Extended) Digest() string {
+	return e.tree.Digest()
+}
+
+// Data returns the number of data shares, which is the number of blocks.
+func (e *Extended) Data() int {
+	return e.data
+}
+
+// Total returns the number of shares, data and parity.
+func (e *Extended) Total() int {
+	return len(e.shares)
+}
+
+// Share returns the share at the given index along with its inclusion proof.
+// The first Data() shares are the original blocks. It returns nil if the
+// index is out of range.
+func (e *Extended) Share(index int) ([]byte, *Proof) {
+	if index < 0 || index >= len(e.shares) {
+		return nil, nil
+	}
+
+	return e.shares[index], e.tree.Proof(index)
+}
+
+// Extend returns the blocks extended with the given number of parity shares.
+// Shorter blocks are padded with zeros to the length of the longest. Share i
+// holds, at each byte position, the value at i of the lowest degree
+// polynomial through the blocks' bytes at 0 to len(blocks)-1. It returns nil
+// if there are no blocks or more than MaxShares shares.
+func Extend(blocks [][]byte, parity int) *Extended {
+	if len(blocks) == 0 || parity < 0 || len(blocks)+parity > MaxShares {
+		return nil
+	}
+
+	size := 0
+	for i := range blocks {
+		if len(blocks[i]) > size {
+			size = len(blocks[i])
+		}
+	}
+
+	e := new(Extended)
+	e.data = len(blocks)
+
+	for i := range blocks {
+		share := make([]byte, size)
+		copy(share, blocks[i])
+		e.shares = append(e.shares, share)
+	}
+
+	e.shares = append(e.shares, encodeShares(e.shares, points(e.data), e.data, e.data+parity)...)
+	e.tree = NewMerkle(e.shares)
+
+	return e
+}
+
+// encodeShares returns the shares at the indices from start up to end of
+// the polynomial passing through the known shares at the given points.
+func encodeShares(known [][]byte, at []byte, start, end int) [][]byte {
+	var shares [][]byte
+
+	for x := start; x < end; x++ {
+		coefficients := lagrange(at, byte(x))
+		share := make([]byte, len(known[0]))
+
+		for i, c := range coefficients {
+			for b := range share {
+				share[b] ^= gfMul(c, known[i][b])
+			}
+		}
+
+		shares = append(shares, share)
+	}
+
+	return shares
+}
+
+// points returns the field elements 0 to n-1.
+func points(n int) []byte {
+	p := make([]byte, n)
+	for i := range p {
+		p[i] = byte(i)
+	}
+
+	return p
+}
+
+// Reconstruct rebuilds the blocks committed to by digest from any data of the
+// total shares, keyed by share index. The blocks are re-extended and checked
+// against the digest, so a wrong share or an incorrectly encoded commitment
+// is detected. It returns false if there are too few shares or the check
+// fails.
+//
+// The blocks are returned as they were committed to, padded with zeros to
+// the length of the longest block. Their original lengths are not part of
+// the commitment, so callers that need them must keep them separately.
+func Reconstruct(digest string, data, total int, shares map[int][]byte) ([][]byte, bool) {
+	if data <= 0 || total < data || total > MaxShares {
+		return nil, false
+	}
+
+	var known [][]byte
+	var at []byte
+
+	for i := 0; i < total && len(known) < data; i++ {
+		if share, ok := shares[i]; ok {
+			if len(known) > 0 && len(share) != len(known[0]) {
+				return nil, false
+			}
+
+			known = append(known, share)
+			at = append(at, byte(i))
+		}
+	}
+
+	if len(known) < data {
+		return nil, false
+	}
+
+	// Interpolate the missing data shares one at a time.
+	blocks := make([][]byte, data)
+	for x := 0; x < data; x++ {
+		if share, ok := shares[x]; ok {
+			blocks[x] = share
+			continue
+		}
+
+		blocks[x] = encodeShares(known, at, x, x+1)[0]
+	}
+
+	e := Extend(blocks, total-data)
+	if e == nil || e.Digest() != digest {
+		return nil, false
+	}
+
+	return blocks, true
+}
+
+// VerifyShare returns true if the proof shows that share is stored at the
+// given index of the extended shares with the given digest and total.
+func VerifyShare(digest string, total, index int, share []byte, p *Proof) bool {
+	if p == nil || p.Index != index || p.Size != total {
+		return false
+	}
+
+	return p.Verify(share, digest)
+}
+
+// Sample returns count distinct share indices chosen at random from total
+// shares. The indices are drawn from crypto/rand, since a server that can
+// guess the samples can withhold every other share.
+func Sample(total, count int) []int {
+	count = max(min(count, total), 0)
+
+	indices := make([]int, total)
+	for i := range indices {
+		indices[i] = i
+	}
+
+	// Shuffle only as far as the samples that are needed.
+	for i := 0; i < count; i++ {
+		// crypto/rand does not fail on supported platforms.
+		j, _ := rand.Int(rand.Reader, big.NewInt(int64(total-i)))
+		k := i + int(j.Int64())
+		indices[i], indices[k] = indices[k], indices[i]
+	}
+
+	return indices[:count]
+}
+
+// Confidence returns the probability that sampling the given number of
+// distinct shares would have hit a withheld share if the blocks could not be
+// reconstructed. To prevent reconstruction at least total-data+1 shares must
+// be withheld, leaving at most data-1 shares for every sample to land on.
+func Confidence(data, total, samples int) float64 {
+	miss := 1.0
+
+	for i := 0; i < samples; i++ {
+		if data-1-i <= 0 {
+			return 1
+		}
+
+		miss *= float64(data-1-i) / float64(total-i)
+	}
+
+	return 1 - miss
+}
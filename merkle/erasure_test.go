@@ -0,0 +1,132 @@
+package merkle
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+func TestExtend(t *testing.T) {
+	blocks := testBlocks(10)
+	blocks[9] = []byte("short")
+
+	e := Extend(blocks, 10)
+
+	if e.Data() != 10 || e.Total() != 20 {
+		t.Fatal("Expected ", 20, "shares got", e.Total())
+	}
+
+	// The data shares are the padded blocks.
+	for i := range blocks {
+		share, _ := e.Share(i)
+
+		if !bytes.Equal(share[:len(blocks[i])], blocks[i]) || len(share) != len(blocks[0]) {
+			t.Fatalf("Share %d is not the padded block.", i)
+		}
+	}
+
+	// Every share verifies against the commitment.
+	for i := 0; i < e.Total(); i++ {
+		share, p := e.Share(i)
+
+		if !VerifyShare(e.Digest(), e.Total(), i, share, p) {
+			t.Fatalf("Share %d did not verify.", i)
+		}
+	}
+
+	share, p := e.Share(12)
+	if VerifyShare(e.Digest(), e.Total(), 13, share, p) || VerifyShare(e.Digest(), 21, 12, share, p) {
+		t.Error("Share verified at the wrong position.")
+	}
+
+	if s, p := e.Share(20); s != nil || p != nil {
+		t.Error("Expected no share for an out of range index.")
+	}
+
+	if Extend(nil, 2) != nil || Extend(testBlocks(200), 57) != nil {
+		t.Error("Expected no extension for invalid sizes.")
+	}
+}
+
+func TestReconstruct(t *testing.T) {
+	blocks := testBlocks(8)
+	e := Extend(blocks, 8)
+
+	// Any 8 of the 16 shares rebuild the blocks.
+	for trial := 0; trial < 20; trial++ {
+		shares := make(map[int][]byte)
+
+		for _, i := range Sample(e.Total(), e.Data()) {
+			shares[i], _ = e.Share(i)
+		}
+
+		rebuilt, ok := Reconstruct(e.Digest(), e.Data(), e.Total(), shares)
+		if !ok {
+			t.Fatal("Could not reconstruct from", len(shares), "shares.")
+		}
+
+		for i := range blocks {
+			if !bytes.Equal(rebuilt[i], blocks[i]) {
+				t.Fatalf("Block %d was not rebuilt.", i)
+			}
+		}
+	}
+
+	// Seven shares are not enough.
+	shares := make(map[int][]byte)
+	for i := 9; i < 16; i++ {
+		shares[i], _ = e.Share(i)
+	}
+
+	if _, ok := Reconstruct(e.Digest(), e.Data(), e.Total(), shares); ok {
+		t.Error("Reconstructed from too few shares.")
+	}
+
+	// Short blocks come back padded to the longest.
+	short := [][]byte{[]byte("short"), []byte("longer block")}
+	e2 := Extend(short, 2)
+	padded := make(map[int][]byte)
+	padded[1], _ = e2.Share(1)
+	padded[3], _ = e2.Share(3)
+
+	rebuilt, ok := Reconstruct(e2.Digest(), 2, 4, padded)
+	if !ok || !bytes.Equal(rebuilt[0], []byte("short\x00\x00\x00\x00\x00\x00\x00")) {
+		t.Errorf("Expected a padded block, got %q", rebuilt)
+	}
+
+	// A corrupted share is caught by the commitment.
+	shares[8] = []byte("wrong share of the right size!!")[:len(blocks[0])]
+	if _, ok := Reconstruct(e.Digest(), e.Data(), e.Total(), shares); ok {
+		t.Error("Reconstructed from a corrupted share.")
+	}
+}
+
+func TestSample(t *testing.T) {
+	seen := make(map[int]bool)
+
+	for _, i := range Sample(16, 10) {
+		if seen[i] || i < 0 || i >= 16 {
+			t.Fatal("Sample returned a repeated or out of range index", i)
+		}
+		seen[i] = true
+	}
+
+	if len(Sample(4, 10)) != 4 {
+		t.Error("Sample returned more indices than shares.")
+	}
+
+	// The samples are not predictable from one call to the next.
+	if fmt.Sprint(Sample(256, 16)) == fmt.Sprint(Sample(256, 16)) {
+		t.Error("Sample returned the same indices twice.")
+	}
+
+	// With a 2x extension each sample at least halves the chance of missing
+	// withheld shares.
+	if c := Confidence(64, 128, 10); c < 1-1.0/1024 {
+		t.Error("Confidence too low", c)
+	}
+
+	if Confidence(64, 128, 0) != 0 || Confidence(2, 4, 2) != 1 {
+		t.Error("Unexpected confidence at the edges.")
+	}
+}
@@ -0,0 +1,101 @@
+package merkle
+
+import (
+	"errors"
+)
+
+// The errors returned by the checked constructors and operations.
+var (
+	// ErrEmpty is returned when a tree is built from no blocks.
+	ErrEmpty = errors.New("merkle: no blocks")
+
+	// ErrIndex is returned when a block index is out of range.
+	ErrIndex = errors.New("merkle: block index out of range")
+
+	// ErrShape is returned when two trees that must hold the same number
+	// of blocks do not.
+	ErrShape = errors.New("merkle: trees hold different numbers of blocks")
+
+	// ErrScheme is returned when two trees hash their blocks differently.
+	ErrScheme = errors.New("merkle: trees use different hashing schemes")
+
+	// ErrSalt is returned when the salts do not match the blocks.
+	ErrSalt = errors.New("merkle: salts do not match the blocks")
+
+	// ErrProof is returned when a proof or witness is malformed or does not
+	// fit the tree it is applied to.
+	ErrProof = errors.New("merkle: invalid proof")
+)
+
+// Build builds a Merkle tree using the slice of byte slices. Unlike
+// NewMerkle, which returns the empty tree, it returns ErrEmpty if there are
+// no blocks.
+func Build(blocks [][]byte) (*Merkle, error) {
+	if len(blocks) == 0 {
+		return nil, ErrEmpty
+	}
+
+	return NewMerkle(blocks), nil
+}
+
+// BuildWithSalts builds a Merkle tree using the slice of byte slices with
+// each block hashed under the salt at the same index. It returns ErrEmpty if
+// there are no blocks and ErrSalt unless there is one SaltSize salt for
+// every block.
+func BuildWithSalts(blocks, salts [][]byte) (*Merkle, error) {
+	if len(blocks) == 0 {
+		return nil, ErrEmpty
+	}
+
+	if len(salts) != len(blocks) {
+		return nil, ErrSalt
+	}
+
+	for i := range salts {
+		if len(salts[i]) != SaltSize {
+			return nil, ErrSalt
+		}
+	}
+
+	return NewMerkleWithSalts(blocks, salts), nil
+}
+
+// Prove returns an inclusion proof for the block at the given index, or
+// ErrIndex if the index is out of range.
+func (m *Merkle) Prove(index int) (*Proof, error) {
+	p := m.Proof(index)
+	if p == nil {
+		return nil, ErrIndex
+	}
+
+	return p, nil
+}
+
+// Set returns a new Merkle tree with the block at the given index replaced,
+// as Update does, or ErrIndex if the index is out of range.
+func (m *Merkle) Set(index int, block []byte) (*Merkle, error) {
+	u := m.Update(index, block)
+	if u == nil {
+		return nil, ErrIndex
+	}
+
+	return u, nil
+}
+
+// Compare returns the encoded digests of the leaves of m2 that differ from
+// those in m, as Diff does. It returns ErrScheme if the trees hash their
+// blocks differently and ErrShape if they hold different numbers of blocks.
+func (m *Merkle) Compare(m2 *Merkle) ([]string, error) {
+	if m.scheme != m2.scheme {
+		return nil, ErrScheme
+	}
+
+	if m.nodes != m2.nodes {
+		return nil, ErrShape
+	}
+
+	var diffs []string
+	m.Diff(m2, &diffs)
+
+	return diffs, nil
+}
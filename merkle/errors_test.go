@@ -0,0 +1,123 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestEmptyTree(t *testing.T) {
+	for _, m := range []*Merkle{NewMerkle(nil), NewMerkle([][]byte{}), NewSaltedMerkle(nil)} {
+		if m.Digest() != EmptyDigest || m.Blocks() != 0 || len(m.Leaves()) != 0 {
+			t.Error("Expected ", EmptyDigest, "got", m.Digest())
+		}
+
+		if m.Proof(0) != nil || m.Update(0, []byte("a")) != nil {
+			t.Error("Expected no blocks in the empty tree.")
+		}
+
+		if faults := m.Fsck(nil); faults != nil {
+			t.Error("Expected ", 0, "got", len(faults))
+		}
+	}
+
+	if NewSaltedMerkle(nil).Append([]byte("a")).Scheme() != Salted {
+		t.Error("Empty salted tree lost its scheme.")
+	}
+
+	f := NewFlatMerkle(nil)
+	if f.Digest() != EmptyDigest || f.Proof(0) != nil || f.Fsck(nil) != nil {
+		t.Error("Expected ", EmptyDigest, "got", f.Digest())
+	}
+
+	// Appending to the empty tree gives the same tree as building it.
+	blocks := testBlocks(5)
+	m := NewMerkle(nil)
+	for _, b := range blocks {
+		m = m.Append(b)
+	}
+
+	if !m.Equal(NewMerkle(blocks)) {
+		t.Error("Expected ", NewMerkle(blocks).Digest(), "got", m.Digest())
+	}
+
+	if !NewMerkle(nil).Equal(NewMerkle(nil)) || NewMerkle(nil).Equal(NewMerkle([][]byte{{}})) {
+		t.Error("Empty tree compared wrongly.")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	blocks := testBlocks(5)
+
+	if _, err := Build(nil); err != ErrEmpty {
+		t.Error("Expected ", ErrEmpty, "got", err)
+	}
+
+	m, err := Build(blocks)
+	if err != nil || !m.Equal(NewMerkle(blocks)) {
+		t.Error("Expected ", nil, "got", err)
+	}
+
+	salts := make([][]byte, len(blocks))
+	for i := range salts {
+		salts[i] = NewSalt()
+	}
+
+	if _, err := BuildWithSalts(nil, nil); err != ErrEmpty {
+		t.Error("Expected ", ErrEmpty, "got", err)
+	}
+
+	if _, err := BuildWithSalts(blocks, salts[:4]); err != ErrSalt {
+		t.Error("Expected ", ErrSalt, "got", err)
+	}
+
+	salts[2] = salts[2][:16]
+	if _, err := BuildWithSalts(blocks, salts); err != ErrSalt {
+		t.Error("Expected ", ErrSalt, "got", err)
+	}
+
+	salts[2] = NewSalt()
+	salted, err := BuildWithSalts(blocks, salts)
+	if err != nil || salted.Scheme() != Salted {
+		t.Error("Expected ", nil, "got", err)
+	}
+
+	for _, i := range []int{-1, 5} {
+		if _, err := m.Prove(i); err != ErrIndex {
+			t.Error("Expected ", ErrIndex, "got", err)
+		}
+
+		if _, err := m.Set(i, []byte("a")); err != ErrIndex {
+			t.Error("Expected ", ErrIndex, "got", err)
+		}
+	}
+
+	p, err := m.Prove(3)
+	if err != nil || !p.Verify(blocks[3], m.Digest()) {
+		t.Error("Expected ", nil, "got", err)
+	}
+
+	u, err := m.Set(3, []byte("a"))
+	if err != nil || u.Equal(m) {
+		t.Error("Expected ", nil, "got", err)
+	}
+
+	diffs, err := m.Compare(u)
+	if err != nil || len(diffs) != 1 {
+		t.Error("Expected ", 1, "got", len(diffs), err)
+	}
+
+	if _, err := m.Compare(salted); err != ErrScheme {
+		t.Error("Expected ", ErrScheme, "got", err)
+	}
+
+	if _, err := m.Compare(NewMerkle(blocks[:4])); err != ErrShape {
+		t.Error("Expected ", ErrShape, "got", err)
+	}
+
+	if _, err := m.Compare(NewMerkle(nil)); err != ErrShape {
+		t.Error("Expected ", ErrShape, "got", err)
+	}
+
+	if _, err := NewFlatMerkle(blocks).Compare(NewFlatMerkle(blocks[:3])); err != ErrShape {
+		t.Error("Expected ", ErrShape, "got", err)
+	}
+}
@@ -0,0 +1,209 @@
+package merkle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExportOptions controls how a Merkle tree is drawn by DOT and Mermaid.
+type ExportOptions struct {
+	// Levels limits the drawing to the given number of levels below the
+	// root. Zero draws the whole tree.
+	Levels int
+
+	// Proof marks the nodes on the path of the proof and the siblings it
+	// uses.
+	Proof *Proof
+
+	// Diff marks the nodes whose digests differ from those in this tree.
+	Diff *Merkle
+}
+
+// The classes a drawn node can be marked with.
+const (
+	markPath    = "path"
+	markSibling = "sibling"
+	markDiff    = "diff"
+)
+
+// exportNode is a node to be drawn along with its parent and marking.
+type exportNode struct {
+	id     string
+	parent string
+	label  string
+	mark   string
+}
+
+// DOT returns the Merkle tree as a Graphviz DOT graph.
+func (m *Merkle) DOT(opts *ExportOptions) string {
+	var b strings.Builder
+
+	fill := map[string]string{markPath: "lightblue", markSibling: "lightgreen", markDiff: "salmon"}
+
+	b.WriteString("digraph merkle {\n")
+	b.WriteString("\tnode [shape=box, fontname=monospace];\n")
+
+	nodes := m.export(opts)
+
+	for _, n := range nodes {
+		style := ""
+		if n.mark != "" {
+			style = fmt.Sprintf(", style=filled, fillcolor=%s", fill[n.mark])
+		}
+
+		fmt.Fprintf(&b, "\t%s [label=\"%s\"%s];\n", n.id, strings.Replace(n.label, "\n", "\\n", -1), style)
+	}
+
+	for _, n := range nodes {
+		if n.parent != "" {
+			fmt.Fprintf(&b, "\t%s -> %s;\n", n.parent, n.id)
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid returns the Merkle tree as a Mermaid flowchart.
+func (m *Merkle) Mermaid(opts *ExportOptions) string {
+	var b strings.Builder
+
+	b.WriteString("graph TD\n")
+
+	nodes := m.export(opts)
+	marked := make(map[string][]string)
+
+	for _, n := range nodes {
+		label := strings.Replace(n.label, "\n", "<br>", -1)
+
+		if n.parent == "" {
+			fmt.Fprintf(&b, "\t%s[\"%s\"]\n", n.id, label)
+		} else {
+			fmt.Fprintf(&b, "\t%s --> %s[\"%s\"]\n", n.parent, n.id, label)
+		}
+
+		if n.mark != "" {
+			marked[n.mark] = append(marked[n.mark], n.id)
+		}
+	}
+
+	b.WriteString("\tclassDef path fill:#add8e6\n")
+	b.WriteString("\tclassDef sibling fill:#90ee90\n")
+	b.WriteString("\tclassDef diff fill:#fa8072\n")
+
+	for _, mark := range []string{markPath, markSibling, markDiff} {
+		if len(marked[mark]) > 0 {
+			fmt.Fprintf(&b, "\tclass %s %s\n", strings.Join(marked[mark], ","), mark)
+		}
+	}
+
+	return b.String()
+}
+
+// export returns the nodes to draw in depth first order. Nodes are named
+// after their level and their index within the level, so the same position
+// has the same name in every tree.
+func (m *Merkle) export(opts *ExportOptions) []exportNode {
+	if opts == nil {
+		opts = new(ExportOptions)
+	}
+
+	marks := make(map[string]string)
+
+	if opts.Diff != nil {
+		level := m.level
+		if opts.Diff.level > level {
+			level = opts.Diff.level
+		}
+
+		markDiffs(m, opts.Diff, level, 0, marks)
+	}
+
+	// Proof marks take precedence over diff marks.
+	if p := opts.Proof; p != nil && p.Index >= 0 && p.Index < m.nodes {
+		for level := 0; level <= m.level; level++ {
+			index := p.Index >> uint(level)
+
+			marks[nodeName(level, index)] = markPath
+			if level < m.level && (index^1)<<uint(level) < m.nodes {
+				marks[nodeName(level, index^1)] = markSibling
+			}
+		}
+	}
+
+	// Count the levels left to draw, including the current one.
+	depth := 0
+	if opts.Levels > 0 {
+		depth = opts.Levels + 1
+	}
+
+	var nodes []exportNode
+	m.exportNode(m.level, 0, "", depth, marks, &nodes)
+
+	return nodes
+}
+
+func (m *Merkle) exportNode(level, index int, parent string, depth int, marks map[string]string, nodes *[]exportNode) {
+	id := nodeName(level, index)
+
+	label := m.encoded[:8]
+	if m.nodes == 0 {
+		label = "empty"
+	} else if m.left == nil {
+		label = fmt.Sprintf("block %d\n%s", index, label)
+	}
+
+	truncated := depth == 1 && m.left != nil
+	if truncated {
+		label = fmt.Sprintf("%s\n%d blocks", label, m.nodes)
+	}
+
+	*nodes = append(*nodes, exportNode{id: id, parent: parent, label: label, mark: marks[id]})
+
+	if m.left == nil || truncated {
+		return
+	}
+
+	if depth > 0 {
+		depth--
+	}
+
+	m.left.exportNode(level-1, 2*index, id, depth, marks, nodes)
+
+	if m.right != nil {
+		m.right.exportNode(level-1, 2*index+1, id, depth, marks, nodes)
+	}
+}
+
+// markDiffs marks the positions in a whose digests differ from those at the
+// same position in b.
+func markDiffs(a, b *Merkle, level, index int, marks map[string]string) {
+	if a == nil {
+		return
+	}
+
+	if b != nil && a.nodes == b.nodes && a.digest == b.digest {
+		return
+	}
+
+	// Only mark positions that are drawn for a.
+	if a.level == level {
+		marks[nodeName(level, index)] = markDiff
+	}
+
+	if level == 0 {
+		return
+	}
+
+	aLeft, aRight := children(a, level)
+	bLeft, bRight := children(b, level)
+
+	markDiffs(aLeft, bLeft, level-1, 2*index, marks)
+	markDiffs(aRight, bRight, level-1, 2*index+1, marks)
+}
+
+// nodeName returns the name used for the node at the given position.
+func nodeName(level, index int) string {
+	return fmt.Sprintf("n%d_%d", level, index)
+}
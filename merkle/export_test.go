@@ -0,0 +1,70 @@
+package merkle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	m := NewMerkle(testBlocks(5))
+
+	dot := m.DOT(nil)
+
+	// 5 leaves, 3 nodes on level 1, 2 on level 2 and the root.
+	if strings.Count(dot, "[label=") != 11 || strings.Count(dot, "->") != 10 {
+		t.Error("Unexpected DOT graph:\n", dot)
+	}
+
+	if !strings.Contains(dot, "n3_0 -> n2_1;") || !strings.Contains(dot, "block 4") {
+		t.Error("DOT graph is missing the carried up block:\n", dot)
+	}
+
+	// Truncating to the root and one level below.
+	dot = m.DOT(&ExportOptions{Levels: 1})
+	if strings.Count(dot, "[label=") != 3 || !strings.Contains(dot, "4 blocks") {
+		t.Error("Unexpected truncated DOT graph:\n", dot)
+	}
+
+	// The proof for block 2 touches the path n0_2, n1_1, n2_0, n3_0 and the
+	// siblings n0_3, n1_0, n2_1.
+	dot = m.DOT(&ExportOptions{Proof: m.Proof(2)})
+	for _, id := range []string{"n0_2", "n1_1", "n2_0", "n3_0"} {
+		if !strings.Contains(lineFor(dot, id), "lightblue") {
+			t.Error(id, "is not marked as on the path:\n", dot)
+		}
+	}
+
+	for _, id := range []string{"n0_3", "n1_0", "n2_1"} {
+		if !strings.Contains(lineFor(dot, id), "lightgreen") {
+			t.Error(id, "is not marked as a sibling:\n", dot)
+		}
+	}
+
+	if strings.Contains(lineFor(dot, "n0_0"), "fillcolor") {
+		t.Error("n0_0 should not be marked:\n", dot)
+	}
+
+	// Changing block 1 marks it and its ancestors in the other tree.
+	blocks := testBlocks(5)
+	blocks[1] = []byte("changed")
+
+	mermaid := m.Mermaid(&ExportOptions{Diff: NewMerkle(blocks)})
+	if !strings.Contains(mermaid, "class n3_0,n2_0,n1_0,n0_1 diff") {
+		t.Error("Unexpected diff marks:\n", mermaid)
+	}
+
+	if !strings.HasPrefix(mermaid, "graph TD\n") || strings.Count(mermaid, "-->") != 10 {
+		t.Error("Unexpected Mermaid graph:\n", mermaid)
+	}
+}
+
+// lineFor returns the DOT line that declares the node with the given name.
+func lineFor(dot, id string) string {
+	for _, line := range strings.Split(dot, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), id+" [") {
+			return line
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,34 @@
+package merkle
+
+import (
+	"io/ioutil"
+)
+
+// Split divides data into blocks of the given size. The last block holds
+// whatever remains and may be shorter. Empty data, or a size of zero or
+// less, yields a single block so that every input has a Merkle tree.
+func Split(data []byte, size int) [][]byte {
+	if len(data) == 0 || size <= 0 {
+		return [][]byte{data}
+	}
+
+	var blocks [][]byte
+
+	for len(data) > size {
+		blocks = append(blocks, data[:size])
+		data = data[size:]
+	}
+
+	return append(blocks, data)
+}
+
+// NewMerkleFromFile returns a new Merkle tree built from the contents of the
+// given file split into blocks of chunk bytes.
+func NewMerkleFromFile(filename string, chunk int) (*Merkle, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMerkle(Split(content, chunk)), nil
+}
@@ -0,0 +1,59 @@
+package merkle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	blocks := Split([]byte("aaaabbbbcc"), 4)
+
+	if len(blocks) != 3 {
+		t.Fatal("Expected ", 3, "got", len(blocks))
+	}
+
+	if string(blocks[0]) != "aaaa" || string(blocks[2]) != "cc" {
+		t.Error("Blocks were not split on chunk boundaries.")
+	}
+
+	if len(Split([]byte("aaaabbbb"), 4)) != 2 {
+		t.Error("Exact multiple of the chunk size should not add a block.")
+	}
+
+	if len(Split([]byte("aaaabbbb"), 0)) != 1 {
+		t.Error("A size of zero should yield a single block.")
+	}
+
+	if len(Split(nil, 4)) != 1 {
+		t.Error("Empty data should yield a single block.")
+	}
+}
+
+func TestNewMerkleFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "merkle")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "data")
+	if err := ioutil.WriteFile(filename, []byte("aaaabbbbcc"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewMerkleFromFile(filename, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := NewMerkle([][]byte{[]byte("aaaa"), []byte("bbbb"), []byte("cc")})
+	if !m.Equal(expected) {
+		t.Errorf("Expected digest %s, received %s.", expected.Digest(), m.Digest())
+	}
+
+	if _, err := NewMerkleFromFile(filepath.Join(dir, "missing"), 4); err == nil {
+		t.Error("Expected an error for a missing file.")
+	}
+}
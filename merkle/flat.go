@@ -0,0 +1,206 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+)
+
+// FlatMerkle is a binary Merkle tree stored as one contiguous slice of
+// digests. It produces the same digests as Merkle but needs a single
+// allocation for the whole tree and only hex encodes digests on request,
+// which makes it much cheaper to hold large trees in memory.
+//
+// The digests are laid out level by level starting with the leaves. The
+// node at index i of a level has its children at 2i and 2i+1 of the level
+// below.
+type FlatMerkle struct {
+	hashes  []byte
+	offsets []int
+	size    int
+}
+
+func (m *FlatMerkle) String() string {
+	return fmt.Sprintf("Depth: %d Nodes: %d Digest: %s", len(m.offsets)-1, m.size, m.Digest())
+}
+
+// Equal returns true if two Merkle trees are equivalent
+func (m *FlatMerkle) Equal(m2 *FlatMerkle) bool {
+	return bytes.Equal(m.root(), m2.root())
+}
+
+// Digest returns the hex encoded digest of the Merkle tree
+func (m *FlatMerkle) Digest() string {
+	return hex.EncodeToString(m.root())
+}
+
+// Diff returns a slice of encoded digests from m2 which are different from
+// those in m1. Subtrees with matching digests are skipped. Trees holding
+// different numbers of blocks are not compared; use Compare to have them
+// reported as an error.
+func (m *FlatMerkle) Diff(m2 *FlatMerkle, diffs *[]string) {
+	if m.size == 0 || m.size != m2.size {
+		return
+	}
+
+	m.diff(m2, len(m.offsets)-1, 0, diffs)
+}
+
+// Compare returns the encoded digests of the leaves of m2 that differ from
+// those in m, as Diff does, or ErrShape if the trees hold different numbers
+// of blocks.
+func (m *FlatMerkle) Compare(m2 *FlatMerkle) ([]string, error) {
+	if m.size != m2.size {
+		return nil, ErrShape
+	}
+
+	var diffs []string
+	m.Diff(m2, &diffs)
+
+	return diffs, nil
+}
+
+func (m *FlatMerkle) diff(m2 *FlatMerkle, level, i int, diffs *[]string) {
+	if bytes.Equal(m.node(level, i), m2.node(level, i)) {
+		return
+	}
+
+	// We are in a leaf node. Record the difference.
+	if level == 0 {
+		*diffs = append(*diffs, hex.EncodeToString(m2.node(level, i)))
+		return
+	}
+
+	m.diff(m2, level-1, 2*i, diffs)
+
+	if 2*i+1 < m.count(level-1) {
+		m.diff(m2, level-1, 2*i+1, diffs)
+	}
+}
+
+// Proof returns an inclusion proof for the block at the given index. It
+// returns nil if the index is out of range.
+func (m *FlatMerkle) Proof(index int) *Proof {
+	if index < 0 || index >= m.size {
+		return nil
+	}
+
+	p := &Proof{Index: index, Size: m.size}
+
+	i := index
+	for level := 0; level < len(m.offsets)-1; level++ {
+		sibling := i ^ 1
+
+		if sibling < m.count(level) {
+			p.Hashes = append(p.Hashes, hex.EncodeToString(m.node(level, sibling)))
+		}
+
+		i /= 2
+	}
+
+	return p
+}
+
+// root returns the digest of the root node.
+func (m *FlatMerkle) root() []byte {
+	if m.size == 0 {
+		return make([]byte, sha256.Size)
+	}
+
+	return m.node(len(m.offsets)-1, 0)
+}
+
+// node returns the digest of the node at index i of the given level.
+func (m *FlatMerkle) node(level, i int) []byte {
+	start := (m.offsets[level] + i) * sha256.Size
+
+	return m.hashes[start : start+sha256.Size]
+}
+
+// count returns the number of nodes in the given level.
+func (m *FlatMerkle) count(level int) int {
+	return (m.size + (1 << uint(level)) - 1) >> uint(level)
+}
+
+// layout sets the number of blocks and works out where each level starts so
+// the digests fit in one slice. It returns the total number of digests.
+func (m *FlatMerkle) layout(size int) int {
+	m.size = size
+	m.offsets = nil
+
+	total := 0
+	for level := 0; ; level++ {
+		m.offsets = append(m.offsets, total)
+		total += m.count(level)
+
+		if m.count(level) <= 1 {
+			break
+		}
+	}
+
+	return total
+}
+
+// MarshalBinary encodes the tree as its number of blocks, as a uvarint,
+// followed by every digest in the order they are stored.
+func (m *FlatMerkle) MarshalBinary() ([]byte, error) {
+	data := binary.AppendUvarint(nil, uint64(m.size))
+
+	return append(data, m.hashes...), nil
+}
+
+// UnmarshalBinary decodes a tree encoded by MarshalBinary. Only the layout
+// is checked; use Fsck to check the digests.
+func (m *FlatMerkle) UnmarshalBinary(data []byte) error {
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)) {
+		return fmt.Errorf("merkle: invalid flat tree header")
+	}
+
+	decoded := new(FlatMerkle)
+	total := decoded.layout(int(size))
+	if len(data)-n != total*sha256.Size {
+		return fmt.Errorf("merkle: flat tree of %d blocks needs %d bytes, got %d", size, total*sha256.Size, len(data)-n)
+	}
+
+	decoded.hashes = make([]byte, len(data)-n)
+	copy(decoded.hashes, data[n:])
+	*m = *decoded
+
+	return nil
+}
+
+// NewFlatMerkle builds a flat Merkle tree using the slice of byte slices.
+func NewFlatMerkle(blocks [][]byte) *FlatMerkle {
+	m := new(FlatMerkle)
+	m.hashes = make([]byte, m.layout(len(blocks))*sha256.Size)
+
+	// Build our leaf nodes
+	for i := range blocks {
+		digest := sha256.Sum256(blocks[i])
+		copy(m.node(0, i), digest[:])
+	}
+
+	// Build each parent level from the one below it.
+	var pair [2 * sha256.Size]byte
+	for level := 1; level < len(m.offsets); level++ {
+		below := m.count(level - 1)
+
+		for i := 0; i < m.count(level); i++ {
+			// Carry the remaining node up when there are an uneven number.
+			if 2*i+1 == below {
+				copy(m.node(level, i), m.node(level-1, 2*i))
+				continue
+			}
+
+			copy(pair[:], m.node(level-1, 2*i))
+			copy(pair[sha256.Size:], m.node(level-1, 2*i+1))
+			digest := sha256.Sum256(pair[:])
+			copy(m.node(level, i), digest[:])
+		}
+	}
+
+	return m
+}
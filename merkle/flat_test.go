@@ -0,0 +1,88 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestFlatMerkle(t *testing.T) {
+	// The flat tree must produce the same digests and proofs as Merkle.
+	for size := 1; size <= 33; size++ {
+		blocks := testBlocks(size)
+		m := NewMerkle(blocks)
+		f := NewFlatMerkle(blocks)
+
+		if f.Digest() != m.Digest() {
+			t.Fatalf("Expected digest %s, received %s.", m.Digest(), f.Digest())
+		}
+
+		if f.String() != m.String() {
+			t.Fatalf("Expected %s, received %s.", m.String(), f.String())
+		}
+
+		for i := range blocks {
+			p := f.Proof(i)
+
+			if !p.Verify(blocks[i], f.Digest()) {
+				t.Fatalf("Proof for block %d of %d did not verify.", i, size)
+			}
+		}
+	}
+
+	blocks1 := testBlocks(9)
+	blocks2 := testBlocks(9)
+	blocks2[2] = []byte("changed")
+	blocks2[8] = []byte("changed")
+
+	f1 := NewFlatMerkle(blocks1)
+	f2 := NewFlatMerkle(blocks1)
+	f3 := NewFlatMerkle(blocks2)
+
+	if !f1.Equal(f2) {
+		t.Error("Merkle trees should be equal.")
+	}
+
+	if f1.Equal(f3) {
+		t.Error("Merkle trees should not be equal.")
+	}
+
+	var diffs []string
+	f1.Diff(f2, &diffs)
+
+	if len(diffs) != 0 {
+		t.Error("Differences found in identical trees.")
+	}
+
+	var expected []string
+	NewMerkle(blocks1).Diff(NewMerkle(blocks2), &expected)
+
+	f1.Diff(f3, &diffs)
+
+	if len(diffs) != len(expected) {
+		t.Fatal("Expected ", len(expected), "got", len(diffs))
+	}
+
+	for i := range diffs {
+		if diffs[i] != expected[i] {
+			t.Error("Expected ", expected[i], "got", diffs[i])
+		}
+	}
+}
+
+func benchmarkFlatMerkle(size int, b *testing.B) {
+	blocks := make([][]byte, size)
+	for i := range blocks {
+		blocks[i] = []byte("aaaaa")
+	}
+
+	for i := 0; i < b.N; i++ {
+		NewFlatMerkle(blocks)
+	}
+}
+
+func BenchmarkFlatMerkle1000(b *testing.B) {
+	benchmarkFlatMerkle(1000, b)
+}
+
+func BenchmarkFlatMerkle100000(b *testing.B) {
+	benchmarkFlatMerkle(100000, b)
+}
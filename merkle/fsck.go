@@ -0,0 +1,182 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Fault describes a node of a Merkle tree whose stored state is not
+// consistent with the rest of the tree. Nodes are identified by their level,
+// counting up from the leaves, and their index within the level.
+type Fault struct {
+	Level  int
+	Index  int
+	Reason string
+}
+
+func (f Fault) String() string {
+	return fmt.Sprintf("level %d index %d: %s", f.Level, f.Index, f.Reason)
+}
+
+// Fsck checks every node of the Merkle tree. Interior digests are recomputed
+// from their children, level and node counts are checked against the shape
+// of the tree, and the cached hex encodings are checked against the digests.
+// If blocks is not nil the leaves are also checked against the blocks. It
+// returns the faults found, or nil if the tree is consistent.
+func (m *Merkle) Fsck(blocks [][]byte) []Fault {
+	var faults []Fault
+
+	if m.nodes == 0 && m.left == nil && m.right == nil {
+		if m.digest != [32]byte{} || m.encoded != EmptyDigest {
+			faults = append(faults, Fault{0, 0, "empty tree does not have the empty digest"})
+		}
+
+		if len(blocks) != 0 {
+			faults = append(faults, Fault{0, 0, fmt.Sprintf("tree has 0 blocks, expected %d", len(blocks))})
+		}
+
+		return faults
+	}
+
+	// The root must be exactly as tall as its node count requires.
+	level := 0
+	for 1<<uint(level) < m.nodes {
+		level++
+	}
+
+	if m.level != level {
+		faults = append(faults, Fault{level, 0, fmt.Sprintf("level is %d for %d blocks", m.level, m.nodes)})
+	}
+
+	if blocks != nil && len(blocks) != m.nodes {
+		faults = append(faults, Fault{level, 0, fmt.Sprintf("tree has %d blocks, expected %d", m.nodes, len(blocks))})
+		blocks = nil
+	}
+
+	m.fsck(level, 0, m.scheme, blocks, &faults)
+
+	return faults
+}
+
+func (m *Merkle) fsck(level, index int, scheme Scheme, blocks [][]byte, faults *[]Fault) {
+	fault := func(format string, args ...interface{}) {
+		*faults = append(*faults, Fault{level, index, fmt.Sprintf(format, args...)})
+	}
+
+	if m.encoded != hex.EncodeToString(m.digest[:]) {
+		fault("hex encoding does not match digest")
+	}
+
+	if m.level != level {
+		fault("level is %d", m.level)
+	}
+
+	if m.scheme != scheme {
+		fault("scheme does not match the tree")
+	}
+
+	if m.left == nil {
+		if m.right != nil {
+			fault("right child without a left child")
+			return
+		}
+
+		if level != 0 || m.nodes != 1 {
+			fault("leaf holds %d blocks", m.nodes)
+		}
+
+		if index < len(blocks) && m.digest != m.leafDigest(blocks[index]) {
+			fault("digest does not match block")
+		}
+
+		return
+	}
+
+	if level == 0 {
+		fault("leaf has children")
+		return
+	}
+
+	m.left.fsck(level-1, 2*index, scheme, blocks, faults)
+
+	if m.right == nil {
+		if m.nodes != m.left.nodes {
+			fault("holds %d blocks, child holds %d", m.nodes, m.left.nodes)
+		}
+
+		if m.digest != m.left.digest {
+			fault("digest does not match child")
+		}
+
+		return
+	}
+
+	m.right.fsck(level-1, 2*index+1, scheme, blocks, faults)
+
+	if m.left.nodes != 1<<uint(level-1) {
+		fault("left child holds %d blocks, expected %d", m.left.nodes, 1<<uint(level-1))
+	}
+
+	if m.nodes != m.left.nodes+m.right.nodes {
+		fault("holds %d blocks, children hold %d", m.nodes, m.left.nodes+m.right.nodes)
+	}
+
+	if m.digest != sha256.Sum256(append(m.left.digest[:], m.right.digest[:]...)) {
+		fault("digest does not match children")
+	}
+}
+
+// leafDigest returns the digest a leaf should have for block.
+func (m *Merkle) leafDigest(block []byte) [32]byte {
+	if m.scheme == Salted {
+		return saltedDigest(block, m.salt)
+	}
+
+	return sha256.Sum256(block)
+}
+
+// Fsck checks every interior digest of the flat Merkle tree against its
+// children. If blocks is not nil the leaves are also checked against the
+// blocks. It returns the faults found, or nil if the tree is consistent.
+func (m *FlatMerkle) Fsck(blocks [][]byte) []Fault {
+	var faults []Fault
+
+	if blocks != nil && len(blocks) != m.size {
+		faults = append(faults, Fault{len(m.offsets) - 1, 0, fmt.Sprintf("tree has %d blocks, expected %d", m.size, len(blocks))})
+		blocks = nil
+	}
+
+	for i := range blocks {
+		digest := sha256.Sum256(blocks[i])
+
+		if !bytes.Equal(m.node(0, i), digest[:]) {
+			faults = append(faults, Fault{0, i, "digest does not match block"})
+		}
+	}
+
+	var pair [2 * sha256.Size]byte
+	for level := 1; level < len(m.offsets); level++ {
+		below := m.count(level - 1)
+
+		for i := 0; i < m.count(level); i++ {
+			var expected []byte
+
+			if 2*i+1 == below {
+				expected = m.node(level-1, 2*i)
+			} else {
+				copy(pair[:], m.node(level-1, 2*i))
+				copy(pair[sha256.Size:], m.node(level-1, 2*i+1))
+				digest := sha256.Sum256(pair[:])
+				expected = digest[:]
+			}
+
+			if !bytes.Equal(m.node(level, i), expected) {
+				faults = append(faults, Fault{level, i, "digest does not match children"})
+			}
+		}
+	}
+
+	return faults
+}
@@ -0,0 +1,99 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestFsck(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		blocks := testBlocks(size)
+
+		if faults := NewMerkle(blocks).Fsck(blocks); faults != nil {
+			t.Fatal("Faults found in a good tree", faults)
+		}
+
+		if faults := NewSaltedMerkle(blocks).Fsck(blocks); faults != nil {
+			t.Fatal("Faults found in a good salted tree", faults)
+		}
+	}
+
+	blocks := testBlocks(6)
+
+	// Corrupt the digest of the node covering blocks 2 and 3.
+	m := NewMerkle(blocks)
+	m.left.right.digest[0] ^= 1
+
+	faults := m.Fsck(nil)
+	if len(faults) != 3 {
+		t.Fatal("Expected ", 3, "faults got", faults)
+	}
+
+	// The node no longer matches its hex cache or its children, and its
+	// parent no longer matches it.
+	if faults[0] != (Fault{1, 1, "hex encoding does not match digest"}) ||
+		faults[1] != (Fault{1, 1, "digest does not match children"}) ||
+		faults[2] != (Fault{2, 0, "digest does not match children"}) {
+		t.Error("Unexpected faults", faults)
+	}
+
+	// A corrupted leaf is only found when checking against the blocks.
+	m = NewMerkle(blocks)
+	m.right.left.right = newLeafNode([]byte("corrupt"))
+
+	if faults := m.Fsck(blocks); len(faults) < 1 || faults[0] != (Fault{0, 5, "digest does not match block"}) {
+		t.Error("Unexpected faults", faults)
+	}
+
+	// Bad counts and levels are reported at the node and where they no
+	// longer add up in the parent.
+	m = NewMerkle(blocks)
+	m.left.nodes = 3
+	m.right.level = 3
+
+	faults = m.Fsck(nil)
+	if len(faults) != 4 || faults[1] != (Fault{2, 1, "level is 3"}) {
+		t.Error("Unexpected faults", faults)
+	}
+
+	if faults := NewMerkle(blocks).Fsck(blocks[:5]); len(faults) != 1 {
+		t.Error("Expected a fault for the wrong number of blocks", faults)
+	}
+}
+
+func TestFlatFsck(t *testing.T) {
+	blocks := testBlocks(11)
+	f := NewFlatMerkle(blocks)
+
+	data, _ := f.MarshalBinary()
+
+	f2 := new(FlatMerkle)
+	if err := f2.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+
+	if !f2.Equal(f) || f2.String() != f.String() || f2.Fsck(blocks) != nil {
+		t.Fatal("Tree did not survive encoding.")
+	}
+
+	if f2.UnmarshalBinary(data[:len(data)-1]) == nil || f2.UnmarshalBinary(nil) == nil {
+		t.Error("Expected an error for a truncated tree.")
+	}
+
+	// Corrupt the level 1 node covering blocks 4 and 5.
+	f.node(1, 2)[0] ^= 1
+
+	faults := f.Fsck(blocks)
+	if len(faults) != 2 || faults[0] != (Fault{1, 2, "digest does not match children"}) ||
+		faults[1] != (Fault{2, 1, "digest does not match children"}) {
+		t.Error("Unexpected faults", faults)
+	}
+
+	// Corrupt a leaf.
+	f = NewFlatMerkle(blocks)
+	f.node(0, 10)[0] ^= 1
+
+	faults = f.Fsck(blocks)
+	if len(faults) < 1 || faults[0] != (Fault{0, 10, "digest does not match block"}) {
+		t.Error("Unexpected faults", faults)
+	}
+}
@@ -0,0 +1,73 @@
+package merkle
+
+// Arithmetic in GF(2^8), the field the erasure code works over. Elements are
+// bytes, addition is XOR and multiplication uses log and exp tables built
+// from the generator 2 and the polynomial x^8 + x^4 + x^3 + x^2 + 1.
+
+// The reducing polynomial of the field.
+const gfPoly = 0x11d
+
+var gfExp, gfLog = gfTables()
+
+// gfTables returns the exp and log tables of the field. The exp table is
+// doubled so that the sum of two logs can be looked up without reducing it.
+func gfTables() ([512]byte, [256]byte) {
+	var exp [512]byte
+	var log [256]byte
+
+	x := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = byte(x)
+		log[x] = byte(i)
+
+		x <<= 1
+		if x&0x100 != 0 {
+			x ^= gfPoly
+		}
+	}
+
+	for i := 255; i < 512; i++ {
+		exp[i] = exp[i-255]
+	}
+
+	return exp, log
+}
+
+// gfMul returns the product of a and b.
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+// gfDiv returns a divided by b. b must not be zero.
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// lagrange returns the coefficients that evaluate, at x, the polynomial
+// passing through the given points. Each point is a distinct field element.
+func lagrange(points []byte, x byte) []byte {
+	coefficients := make([]byte, len(points))
+
+	for i, xi := range points {
+		c := byte(1)
+
+		for j, xj := range points {
+			if i != j {
+				// Subtraction is addition, which is XOR.
+				c = gfMul(c, gfDiv(x^xj, xi^xj))
+			}
+		}
+
+		coefficients[i] = c
+	}
+
+	return coefficients
+}
@@ -0,0 +1,47 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestGF256(t *testing.T) {
+	// Every non-zero element has an inverse.
+	for a := 1; a < 256; a++ {
+		if gfMul(byte(a), gfDiv(1, byte(a))) != 1 {
+			t.Fatalf("%d times its inverse is not 1.", a)
+		}
+	}
+
+	// Multiplication distributes over addition.
+	for a := 0; a < 256; a += 7 {
+		for b := 0; b < 256; b += 5 {
+			for c := 0; c < 256; c += 11 {
+				x, y, z := byte(a), byte(b), byte(c)
+
+				if gfMul(x, y^z) != gfMul(x, y)^gfMul(x, z) {
+					t.Fatalf("Multiplication does not distribute for %d, %d, %d.", a, b, c)
+				}
+			}
+		}
+	}
+
+	if gfMul(0x80, 2) != 0x1d {
+		t.Error("Expected ", 0x1d, "got", gfMul(0x80, 2))
+	}
+
+	// The polynomial through (1, 5), (2, 7) and (3, 9) evaluated at one of
+	// those points gives back its value.
+	points := []byte{1, 2, 3}
+	values := []byte{5, 7, 9}
+
+	for i, x := range points {
+		var y byte
+		for j, c := range lagrange(points, x) {
+			y ^= gfMul(c, values[j])
+		}
+
+		if y != values[i] {
+			t.Error("Expected ", values[i], "got", y)
+		}
+	}
+}
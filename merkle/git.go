@@ -0,0 +1,184 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The modes git records for tree entries.
+const (
+	GitFile       = "100644"
+	GitExecutable = "100755"
+	GitSymlink    = "120000"
+	GitDirectory  = "40000"
+	GitSubmodule  = "160000"
+)
+
+// GitEntry is an entry of a git tree: the mode, the name and the hex encoded
+// ID of the object it refers to.
+type GitEntry struct {
+	Mode string
+	Name string
+	ID   string
+}
+
+// GitObject returns the hex encoded git object ID of content stored as the
+// given kind of object, such as "blob" or "tree". Git repositories use SHA1
+// or, with the sha256 object format, SHA256. It returns an error for any
+// other hash.
+func GitObject(h Hash, kind string, content []byte) (string, error) {
+	if h != SHA1 && h != SHA256 {
+		return "", fmt.Errorf("merkle: git does not support hash %#x", uint64(h))
+	}
+
+	header := kind + " " + strconv.Itoa(len(content)) + "\x00"
+
+	return hex.EncodeToString(h.Sum(append([]byte(header), content...))), nil
+}
+
+// GitBlob returns the hex encoded git object ID of a file holding data.
+func GitBlob(h Hash, data []byte) (string, error) {
+	return GitObject(h, "blob", data)
+}
+
+// GitTree returns the hex encoded git object ID of a tree holding the
+// entries. The entries are sorted as git sorts them, by name with
+// directories compared as if their names ended in a slash.
+func GitTree(h Hash, entries []GitEntry) (string, error) {
+	// Names are checked before sorting, which can separate a file from a
+	// directory of the same name.
+	names := make(map[string]bool)
+	for _, e := range entries {
+		if names[e.Name] {
+			return "", fmt.Errorf("merkle: duplicate git tree entry %q", e.Name)
+		}
+		names[e.Name] = true
+	}
+
+	sorted := make([]GitEntry, len(entries))
+	copy(sorted, entries)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].sortName() < sorted[j].sortName()
+	})
+
+	size := len(h.Sum(nil))
+
+	var content []byte
+	for _, e := range sorted {
+		if e.Name == "" || e.Name == "." || e.Name == ".." || strings.ContainsAny(e.Name, "/\x00") {
+			return "", fmt.Errorf("merkle: invalid git tree entry name %q", e.Name)
+		}
+
+		id, err := hex.DecodeString(e.ID)
+		if err != nil || len(id) != size {
+			return "", fmt.Errorf("merkle: invalid object id %q for %q", e.ID, e.Name)
+		}
+
+		content = append(content, e.Mode+" "+e.Name+"\x00"...)
+		content = append(content, id...)
+	}
+
+	return GitObject(h, "tree", content)
+}
+
+// sortName returns the name the entry is sorted by within a tree.
+func (e GitEntry) sortName() string {
+	if e.Mode == GitDirectory {
+		return e.Name + "/"
+	}
+
+	return e.Name
+}
+
+// GitTreeDir returns the hex encoded git object ID of the tree git would
+// record for the directory. Files are stored as blobs with the executable
+// mode if any execute bit is set, and symlinks as blobs holding their
+// target. As in git, directories with no files are left out and .git
+// directories are skipped. Files are hashed as they are on disk, without any
+// line ending conversion or other filters a repository may apply. It
+// returns an error for any other kind of file.
+func GitTreeDir(h Hash, dir string) (string, error) {
+	id, _, err := gitTreeDir(h, dir)
+
+	return id, err
+}
+
+// gitTreeDir returns the ID of the tree for dir and the number of entries
+// it holds.
+func gitTreeDir(h Hash, dir string) (string, int, error) {
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return "", 0, err
+	}
+
+	var entries []GitEntry
+
+	for _, f := range files {
+		path := filepath.Join(dir, f.Name())
+		e := GitEntry{Name: f.Name()}
+
+		switch mode := f.Type(); {
+		case mode.IsDir():
+			if f.Name() == ".git" {
+				continue
+			}
+
+			id, n, err := gitTreeDir(h, path)
+			if err != nil {
+				return "", 0, err
+			}
+
+			if n == 0 {
+				continue
+			}
+
+			e.Mode, e.ID = GitDirectory, id
+
+		case mode&os.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return "", 0, err
+			}
+
+			e.Mode = GitSymlink
+			if e.ID, err = GitBlob(h, []byte(filepath.ToSlash(target))); err != nil {
+				return "", 0, err
+			}
+
+		case mode.IsRegular():
+			info, err := f.Info()
+			if err != nil {
+				return "", 0, err
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", 0, err
+			}
+
+			e.Mode = GitFile
+			if info.Mode()&0111 != 0 {
+				e.Mode = GitExecutable
+			}
+
+			if e.ID, err = GitBlob(h, data); err != nil {
+				return "", 0, err
+			}
+
+		default:
+			return "", 0, fmt.Errorf("merkle: git cannot store %s", path)
+		}
+
+		entries = append(entries, e)
+	}
+
+	id, err := GitTree(h, entries)
+
+	return id, len(entries), err
+}
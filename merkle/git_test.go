@@ -0,0 +1,96 @@
+package merkle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGitObjects(t *testing.T) {
+	tests := []struct {
+		h     Hash
+		empty string
+		hello string
+		tree  string
+	}{
+		{SHA1, "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391", "ce013625030ba8dba906f756967f9e9ca394464a",
+			"4b825dc642cb6eb9a060e54bf8d69288fbee4904"},
+		{SHA256, "473a0f4c3be8a93681a267e3b1e9a7dcda1185436fe141f7749120a303721813",
+			"2cf8d83d9ee29543b34a87727421fdecb7e3f3a183d337639025de576db9ebb4",
+			"6ef19b41225c5369f1c104d45d8d85efa9b057b53b14b4b9b939dd74decc5321"},
+	}
+
+	for _, test := range tests {
+		if id, _ := GitBlob(test.h, nil); id != test.empty {
+			t.Error("Expected ", test.empty, "got", id)
+		}
+
+		if id, _ := GitBlob(test.h, []byte("hello\n")); id != test.hello {
+			t.Error("Expected ", test.hello, "got", id)
+		}
+
+		if id, _ := GitTree(test.h, nil); id != test.tree {
+			t.Error("Expected ", test.tree, "got", id)
+		}
+	}
+
+	if _, err := GitBlob(SHA512, nil); err == nil {
+		t.Error("Expected an error for an unsupported hash.")
+	}
+
+	blob, _ := GitBlob(SHA1, nil)
+	for _, entries := range [][]GitEntry{
+		{{GitFile, "a/b", blob}},
+		{{GitFile, "..", blob}},
+		{{GitFile, "a", blob}, {GitExecutable, "a", blob}},
+		{{GitFile, "a", blob}, {GitFile, "a-b", blob}, {GitDirectory, "a", blob}},
+		{{GitFile, "a", blob[:38]}},
+		{{GitFile, "a", "not hex"}},
+	} {
+		if _, err := GitTree(SHA1, entries); err == nil {
+			t.Error("Expected an error for entries", entries)
+		}
+	}
+}
+
+func TestGitTreeDir(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"a.txt":             "hello\n",
+		"run.sh":            "#!/bin/sh\necho hi\n",
+		"sub/b":             "b\n",
+		"sub.txt":           "text\n",
+		".git/HEAD":         "ref: refs/heads/main\n",
+		"empty/.git/config": "",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+
+	os.Chmod(filepath.Join(dir, "run.sh"), 0755)
+	os.Symlink("a.txt", filepath.Join(dir, "link"))
+
+	// The IDs git write-tree gives for the same directory.
+	tests := []struct {
+		h    Hash
+		tree string
+	}{
+		{SHA1, "9a074cf277a849f40c757e0fcf0193f3ec5d7d8e"},
+		{SHA256, "bf6e36e4ef8755460fa3c7a669d8bd0e2556ecb272c31b0a61f5dacb9add07d1"},
+	}
+
+	for _, test := range tests {
+		id, err := GitTreeDir(test.h, dir)
+		if err != nil || id != test.tree {
+			t.Error("Expected ", test.tree, "got", id, err)
+		}
+	}
+
+	if _, err := GitTreeDir(SHA1, filepath.Join(dir, "missing")); err == nil {
+		t.Error("Expected an error for a missing directory.")
+	}
+}
@@ -0,0 +1,66 @@
+package merkle
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Log is an append-only Merkle log that is safe for concurrent use. Appends
+// are serialized with each other, while readers take snapshots without
+// locking and may keep using them as the log grows.
+type Log struct {
+	mu      sync.Mutex
+	current atomic.Pointer[Snapshot]
+}
+
+// Snapshot is an immutable view of a Log at one size.
+type Snapshot struct {
+	tree *Merkle
+}
+
+// Size returns the number of blocks in the snapshot.
+func (s *Snapshot) Size() int {
+	return s.tree.Blocks()
+}
+
+// Digest returns the hex encoded root digest of the snapshot, or EmptyDigest
+// if the snapshot holds no blocks.
+func (s *Snapshot) Digest() string {
+	return s.tree.Digest()
+}
+
+// Proof returns an inclusion proof for the block at the given index. It
+// returns nil if the index is out of range.
+func (s *Snapshot) Proof(index int) *Proof {
+	return s.tree.Proof(index)
+}
+
+// Tree returns the Merkle tree of the snapshot.
+func (s *Snapshot) Tree() *Merkle {
+	return s.tree
+}
+
+// Snapshot returns the current state of the log.
+func (l *Log) Snapshot() *Snapshot {
+	return l.current.Load()
+}
+
+// Append adds block to the end of the log and returns the resulting
+// snapshot. Snapshots taken earlier are not affected.
+func (l *Log) Append(block []byte) *Snapshot {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	s := &Snapshot{tree: l.current.Load().tree.Append(block)}
+	l.current.Store(s)
+
+	return s
+}
+
+// NewLog returns a new, empty Log.
+func NewLog() *Log {
+	l := new(Log)
+	l.current.Store(&Snapshot{tree: NewMerkle(nil)})
+
+	return l
+}
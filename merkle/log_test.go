@@ -0,0 +1,75 @@
+package merkle
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	l := NewLog()
+
+	empty := l.Snapshot()
+	if empty.Size() != 0 || empty.Digest() != EmptyDigest || empty.Proof(0) != nil || empty.Tree().Blocks() != 0 {
+		t.Error("Expected an empty snapshot.")
+	}
+
+	const size = 500
+	var wg sync.WaitGroup
+
+	// One appender and several readers run at the same time. Every snapshot
+	// a reader takes must answer proofs for all of its blocks.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < size; i++ {
+			l.Append([]byte(fmt.Sprintf("block %d", i)))
+		}
+	}()
+
+	failures := make(chan string, 8)
+
+	for r := 0; r < 8; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				s := l.Snapshot()
+				n := s.Size()
+
+				if n > 0 {
+					i := n / 2
+					if !s.Proof(i).Verify([]byte(fmt.Sprintf("block %d", i)), s.Digest()) {
+						failures <- fmt.Sprintf("Proof for block %d of %d did not verify.", i, n)
+						return
+					}
+				}
+
+				if n == size {
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(failures)
+
+	for e := range failures {
+		t.Error(e)
+	}
+
+	if !l.Snapshot().Tree().Equal(NewMerkle(testBlocks(size))) {
+		t.Error("Log does not match a tree built from the same blocks.")
+	}
+
+	// Old snapshots are unaffected by later appends.
+	s := l.Snapshot()
+	l.Append([]byte("more"))
+
+	if s.Size() != size || l.Snapshot().Size() != size+1 {
+		t.Error("Snapshot changed after an append.")
+	}
+}
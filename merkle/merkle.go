@@ -1,6 +1,7 @@
 // Package merkle generates a merkle tree from a list of byte slices. Each
 // byte slice (block) is hashed using SHA256 and the resulting hashes are
-// used to build the Merkle tree.
+// used to build the Merkle tree. A tree with no blocks has the digest
+// EmptyDigest.
 package merkle
 
 import (
@@ -9,6 +10,11 @@ import (
 	"fmt"
 )
 
+// EmptyDigest is the hex encoded digest of a Merkle tree with no blocks, 32
+// zero bytes. No block hashes to it, so the empty tree cannot be mistaken for
+// a tree holding one empty block.
+const EmptyDigest = "0000000000000000000000000000000000000000000000000000000000000000"
+
 // The Merkle type represents a binary Merkle tree.
 type Merkle struct {
 	digest  [32]byte
@@ -17,6 +23,11 @@ type Merkle struct {
 	right   *Merkle
 	level   int
 	nodes   int
+	scheme  Scheme
+	salt    []byte
+
+	// key derives the salts of a keyed tree. Only the root's key is used.
+	key []byte
 }
 
 func (m *Merkle) String() string {
@@ -33,9 +44,45 @@ func (m *Merkle) Digest() string {
 	return m.encoded
 }
 
+// Blocks returns the number of blocks the Merkle tree was built from.
+func (m *Merkle) Blocks() int {
+	return m.nodes
+}
+
+// Leaves returns the hex encoded digests of the leaves in block order.
+func (m *Merkle) Leaves() []string {
+	var leaves []string
+
+	m.collect(&leaves)
+
+	return leaves
+}
+
+func (m *Merkle) collect(leaves *[]string) {
+	if m.nodes == 0 {
+		return
+	}
+
+	if m.left == nil {
+		*leaves = append(*leaves, m.encoded)
+		return
+	}
+
+	m.left.collect(leaves)
+
+	if m.right != nil {
+		m.right.collect(leaves)
+	}
+}
+
 // Diff returns a slice of encoded digests from m2 which are different from
-// those in m1.
+// those in m1. Both trees should hold the same number of blocks; parts of m
+// with no counterpart in m2 are skipped. Use Compare to have mismatched trees
+// reported as an error.
 func (m *Merkle) Diff(m2 *Merkle, diffs *[]string) {
+	if m2 == nil {
+		return
+	}
 
 	if m.left != nil {
 		m.left.Diff(m2.left, diffs)
@@ -51,6 +98,62 @@ func (m *Merkle) Diff(m2 *Merkle, diffs *[]string) {
 	}
 }
 
+// Changed returns the indices of the blocks that differ between m and m2, in
+// ascending order. The trees may hold a different number of blocks; blocks
+// present in only one of the trees are counted as changed. Subtrees with
+// matching digests are skipped.
+func (m *Merkle) Changed(m2 *Merkle) []int {
+	var changed []int
+
+	level := m.level
+	if m2.level > level {
+		level = m2.level
+	}
+
+	changes(m, m2, level, 0, &changed)
+
+	return changed
+}
+
+// changes compares the subtrees a and b, which sit at the same position of
+// their trees, and appends the indices of the differing blocks. Either node
+// may be nil, and a node below the given level stands in for a chain of
+// nodes carried up from it.
+func changes(a, b *Merkle, level, start int, changed *[]int) {
+	if a == nil && b == nil {
+		return
+	}
+
+	if a != nil && b != nil && a.nodes == b.nodes && a.digest == b.digest {
+		return
+	}
+
+	if level == 0 {
+		*changed = append(*changed, start)
+		return
+	}
+
+	aLeft, aRight := children(a, level)
+	bLeft, bRight := children(b, level)
+
+	changes(aLeft, bLeft, level-1, start, changed)
+	changes(aRight, bRight, level-1, start+1<<uint(level-1), changed)
+}
+
+// children returns the children of n when it is viewed as a node at the given
+// level.
+func children(n *Merkle, level int) (*Merkle, *Merkle) {
+	if n == nil {
+		return nil, nil
+	}
+
+	if n.level < level {
+		return n, nil
+	}
+
+	return n.left, n.right
+}
+
 // newLeafNode returns a new leaf node in the Merkle tree created from the
 // given block.
 func newLeafNode(block []byte) *Merkle {
@@ -64,6 +167,14 @@ func newLeafNode(block []byte) *Merkle {
 	return m
 }
 
+// newEmptyNode returns the root of a Merkle tree with no blocks.
+func newEmptyNode() *Merkle {
+	m := new(Merkle)
+	m.encoded = EmptyDigest
+
+	return m
+}
+
 // newMerkleNode returns a new merkle node created from the give leaf nodes.
 func newMerkleNode(leaf1, leaf2 *Merkle) *Merkle {
 	m := new(Merkle)
@@ -80,6 +191,7 @@ func newMerkleNode(leaf1, leaf2 *Merkle) *Merkle {
 	m.left = leaf1
 	m.right = leaf2
 	m.level = leaf1.level + 1
+	m.scheme = leaf1.scheme
 
 	return m
 }
@@ -93,6 +205,15 @@ func NewMerkle(blocks [][]byte) *Merkle {
 		leaves = append(leaves, newLeafNode(blocks[i]))
 	}
 
+	return newMerkleTree(leaves)
+}
+
+// newMerkleTree builds parent nodes over the leaf nodes and returns the root.
+func newMerkleTree(leaves []*Merkle) *Merkle {
+	if len(leaves) == 0 {
+		return newEmptyNode()
+	}
+
 	// Build parent nodes until there is only one parent.
 	for {
 		if len(leaves) == 1 {
@@ -147,6 +147,52 @@ func TestMerkle(t *testing.T) {
 	if len(diffs) != 4 {
 		t.Error("Expected ", 4, "got", len(diffs))
 	}
+
+	fmt.Println("Testing leaves")
+	leaves := m5.Leaves()
+
+	if len(leaves) != 5 || leaves[0] != encode(sum(b1)) || leaves[4] != encode(sum(b5)) {
+		t.Error("Leaves are not the block digests in order.")
+	}
+
+	fmt.Println("Testing changed blocks")
+	changed := m1.Changed(m3)
+
+	if !equalInts(changed, []int{0, 1, 3, 4}) {
+		t.Error("Expected ", []int{0, 1, 3, 4}, "got", changed)
+	}
+
+	if len(m1.Changed(m2)) != 0 {
+		t.Error("Changes found in identical trees.")
+	}
+
+	// Trees of different sizes report the extra blocks as changed.
+	m4 = NewMerkle([][]byte{b1, b2, b3, b4, b5, b1, b2})
+	changed = m1.Changed(m4)
+
+	if !equalInts(changed, []int{5, 6}) {
+		t.Error("Expected ", []int{5, 6}, "got", changed)
+	}
+
+	changed = m4.Changed(NewMerkle([][]byte{b1, b3}))
+
+	if !equalInts(changed, []int{1, 2, 3, 4, 5, 6}) {
+		t.Error("Expected ", []int{1, 2, 3, 4, 5, 6}, "got", changed)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
 }
 
 func benchmarkMerkle(size int, b *testing.B) {
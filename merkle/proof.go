@@ -0,0 +1,132 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Proof is an inclusion proof for a single block of a Merkle tree. Hashes
+// holds the hex encoded digests of the siblings on the path from the leaf to
+// the root, starting at the leaf. Levels where the path node was carried up
+// without a sibling have no entry; they are derived from Index and Size.
+// Salt holds the hex encoded salt of the block in a Salted tree.
+type Proof struct {
+	Index  int      `json:"index"`
+	Size   int      `json:"size"`
+	Hashes []string `json:"hashes"`
+	Salt   string   `json:"salt,omitempty"`
+}
+
+// Verify returns true if the proof shows that block is stored at p.Index in
+// the Merkle tree of p.Size blocks with the given hex encoded digest. Index
+// and Size come from the proof, and an interior node is the digest of the
+// 64 bytes of its children, so a proof for a smaller tree can pass those
+// bytes off as a block. Callers that do not already trust Index and Size
+// must use VerifyAt.
+func (p *Proof) Verify(block []byte, digest string) bool {
+	leaf := sha256.Sum256(block)
+
+	if p.Salt != "" {
+		// The salt must be a whole salt, otherwise bytes could be moved
+		// from the start of the block into it.
+		salt, err := hex.DecodeString(p.Salt)
+		if err != nil || len(salt) != SaltSize {
+			return false
+		}
+
+		leaf = saltedDigest(block, salt)
+	}
+
+	root, ok := p.root(leaf)
+	if !ok {
+		return false
+	}
+
+	return hex.EncodeToString(root[:]) == digest
+}
+
+// VerifyAt returns true if the proof shows that block is stored at index in
+// the Merkle tree of size blocks with the given hex encoded digest. The
+// index and size must come from the caller, not from the proof.
+func (p *Proof) VerifyAt(block []byte, index, size int, digest string) bool {
+	return p.Index == index && p.Size == size && p.Verify(block, digest)
+}
+
+// root folds the sibling hashes into the given leaf digest and returns the
+// resulting root digest. It returns false if the proof is malformed.
+func (p *Proof) root(leaf [32]byte) ([32]byte, bool) {
+	if p.Index < 0 || p.Index >= p.Size {
+		return leaf, false
+	}
+
+	digest := leaf
+	hashes := p.Hashes
+	index, size := p.Index, p.Size
+
+	for size > 1 {
+		// The last node of a level with an odd number of nodes has no
+		// sibling and is carried up unchanged.
+		if index%2 == 1 || index+1 < size {
+			if len(hashes) == 0 {
+				return leaf, false
+			}
+
+			sibling, err := hex.DecodeString(hashes[0])
+			if err != nil || len(sibling) != sha256.Size {
+				return leaf, false
+			}
+			hashes = hashes[1:]
+
+			if index%2 == 1 {
+				digest = sha256.Sum256(append(sibling, digest[:]...))
+			} else {
+				digest = sha256.Sum256(append(digest[:], sibling...))
+			}
+		}
+
+		index /= 2
+		size = (size + 1) / 2
+	}
+
+	return digest, len(hashes) == 0
+}
+
+// Proof returns an inclusion proof for the block at the given index. It
+// returns nil if the index is out of range.
+func (m *Merkle) Proof(index int) *Proof {
+	if index < 0 || index >= m.nodes {
+		return nil
+	}
+
+	p := &Proof{Index: index, Size: m.nodes}
+
+	// Walk down from the root. The left child of a node at level n always
+	// holds exactly 2^(n-1) leaves, so the index tells us which way to go.
+	var path []string
+	n := m
+	for n.left != nil {
+		half := 1 << uint(n.level-1)
+
+		if index < half {
+			if n.right != nil {
+				path = append(path, n.right.encoded)
+			}
+			n = n.left
+		} else {
+			path = append(path, n.left.encoded)
+			n = n.right
+			index -= half
+		}
+	}
+
+	if n.salt != nil {
+		p.Salt = hex.EncodeToString(n.salt)
+	}
+
+	// The proof lists siblings from the leaf up.
+	for i := len(path) - 1; i >= 0; i-- {
+		p.Hashes = append(p.Hashes, path[i])
+	}
+
+	return p
+}
@@ -0,0 +1,84 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func testBlocks(size int) [][]byte {
+	blocks := make([][]byte, size)
+	for i := range blocks {
+		blocks[i] = []byte(fmt.Sprintf("block %d", i))
+	}
+
+	return blocks
+}
+
+func TestProof(t *testing.T) {
+	for size := 1; size <= 33; size++ {
+		blocks := testBlocks(size)
+		m := NewMerkle(blocks)
+
+		for i := range blocks {
+			p := m.Proof(i)
+
+			if !p.Verify(blocks[i], m.Digest()) {
+				t.Fatalf("Proof for block %d of %d did not verify.", i, size)
+			}
+
+			if p.Verify([]byte("bogus"), m.Digest()) {
+				t.Fatalf("Proof for block %d of %d verified the wrong block.", i, size)
+			}
+		}
+	}
+
+	m := NewMerkle(testBlocks(5))
+
+	if m.Proof(-1) != nil || m.Proof(5) != nil {
+		t.Error("Expected no proof for an out of range index.")
+	}
+
+	// A proof with the wrong position or missing hashes must not verify.
+	p := m.Proof(2)
+	p.Index = 3
+	if p.Verify([]byte("block 2"), m.Digest()) {
+		t.Error("Proof verified at the wrong index.")
+	}
+
+	p = m.Proof(2)
+	p.Hashes = p.Hashes[1:]
+	if p.Verify([]byte("block 2"), m.Digest()) {
+		t.Error("Proof verified with a missing hash.")
+	}
+}
+
+func TestProofVerifyAt(t *testing.T) {
+	blocks := testBlocks(4)
+	m := NewMerkle(blocks)
+
+	if p := m.Proof(2); !p.VerifyAt(blocks[2], 2, 4, m.Digest()) || p.VerifyAt(blocks[2], 2, 5, m.Digest()) {
+		t.Error("Expected the proof to verify only at its own index and size.")
+	}
+
+	// The two leaf digests under the left child of the root pass as a block
+	// of a tree of two blocks with the same digest.
+	var leaves [][]byte
+	for _, l := range m.Leaves() {
+		leaf, _ := hex.DecodeString(l)
+		leaves = append(leaves, leaf)
+	}
+
+	uncle := sha256.Sum256(append(append([]byte(nil), leaves[2]...), leaves[3]...))
+	forged := &Proof{Index: 0, Size: 2, Hashes: []string{hex.EncodeToString(uncle[:])}}
+	block := append(append([]byte(nil), leaves[0]...), leaves[1]...)
+
+	if !forged.Verify(block, m.Digest()) {
+		t.Fatal("Expected the interior node to pass Verify.")
+	}
+
+	if forged.VerifyAt(block, 0, 4, m.Digest()) {
+		t.Error("Interior node verified as a block of the tree.")
+	}
+}
@@ -0,0 +1,127 @@
+package merkle
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// Scheme identifies how the blocks of a Merkle tree are hashed into leaves.
+type Scheme int
+
+const (
+	// Plain leaves are the SHA256 digest of the block.
+	Plain Scheme = iota
+
+	// Salted leaves are the SHA256 digest of a per-leaf salt followed by
+	// the block. Without the salt a leaf digest cannot be used to guess a
+	// low-entropy block, so a proof only exposes the revealed block.
+	Salted
+)
+
+// SaltSize is the number of bytes in a random salt.
+const SaltSize = 32
+
+// Scheme returns the way the blocks of the Merkle tree were hashed.
+func (m *Merkle) Scheme() Scheme {
+	return m.scheme
+}
+
+// newSaltedLeafNode returns a new leaf node in the Merkle tree created from
+// the given salt and block.
+func newSaltedLeafNode(block, salt []byte) *Merkle {
+	m := new(Merkle)
+
+	m.digest = saltedDigest(block, salt)
+	m.encoded = hex.EncodeToString(m.digest[:])
+	m.level = 0
+	m.nodes = 1
+	m.scheme = Salted
+	m.salt = salt
+
+	return m
+}
+
+// saltedDigest returns the digest of the salt followed by the block.
+func saltedDigest(block, salt []byte) [32]byte {
+	h := sha256.New()
+	h.Write(salt)
+	h.Write(block)
+
+	var digest [32]byte
+	h.Sum(digest[:0])
+
+	return digest
+}
+
+// NewSalt returns a new random salt.
+func NewSalt() []byte {
+	salt := make([]byte, SaltSize)
+
+	// crypto/rand does not fail on supported platforms.
+	rand.Read(salt)
+
+	return salt
+}
+
+// KeyedSalt returns the salt for the leaf at the given index of a keyed
+// tree, the HMAC-SHA256 of the index under the key. Anyone holding the key
+// can recreate every salt, while a proof reveals only the salt of its own
+// leaf.
+func KeyedSalt(key []byte, index int) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(index))
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(buf[:])
+
+	return mac.Sum(nil)
+}
+
+// NewSaltedMerkle builds a Merkle tree using the slice of byte slices, with
+// each block hashed under a new random salt. The salts are kept in the tree
+// and each proof carries the salt of its block.
+func NewSaltedMerkle(blocks [][]byte) *Merkle {
+	salts := make([][]byte, len(blocks))
+	for i := range salts {
+		salts[i] = NewSalt()
+	}
+
+	return NewMerkleWithSalts(blocks, salts)
+}
+
+// NewKeyedMerkle builds a Merkle tree using the slice of byte slices, with
+// each block hashed under the salt KeyedSalt(key, index). The tree keeps
+// the key, so blocks appended or updated later are salted the same way.
+func NewKeyedMerkle(blocks [][]byte, key []byte) *Merkle {
+	salts := make([][]byte, len(blocks))
+	for i := range salts {
+		salts[i] = KeyedSalt(key, i)
+	}
+
+	m := NewMerkleWithSalts(blocks, salts)
+	m.key = append([]byte(nil), key...)
+
+	return m
+}
+
+// NewMerkleWithSalts builds a Merkle tree using the slice of byte slices,
+// with each block hashed under the salt at the same index. There must be a
+// salt for every block, and salts must be SaltSize bytes long; proofs
+// carrying salts of any other length do not verify. BuildWithSalts checks
+// the salts.
+func NewMerkleWithSalts(blocks, salts [][]byte) *Merkle {
+	var leaves []*Merkle
+
+	for i := range blocks {
+		leaves = append(leaves, newSaltedLeafNode(blocks[i], salts[i]))
+	}
+
+	// An empty tree has no leaves to take the scheme from.
+	m := newMerkleTree(leaves)
+	m.scheme = Salted
+
+	return m
+}
@@ -0,0 +1,137 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestSaltedMerkle(t *testing.T) {
+	blocks := testBlocks(7)
+	m := NewSaltedMerkle(blocks)
+
+	if m.Scheme() != Salted || NewMerkle(blocks).Scheme() != Plain {
+		t.Error("Unexpected scheme.")
+	}
+
+	// Random salts give a different tree every time.
+	if m.Equal(NewSaltedMerkle(blocks)) || m.Equal(NewMerkle(blocks)) {
+		t.Error("Salted trees should not be equal.")
+	}
+
+	for i := range blocks {
+		p := m.Proof(i)
+
+		if len(p.Salt) != 2*SaltSize {
+			t.Fatal("Proof does not carry the salt of its block.")
+		}
+
+		if !p.Verify(blocks[i], m.Digest()) {
+			t.Fatalf("Proof for block %d did not verify.", i)
+		}
+	}
+
+	// A proof only carries its own salt.
+	if m.Proof(0).Salt == m.Proof(1).Salt {
+		t.Error("Blocks share a salt.")
+	}
+
+	// Without the salt the proof does not verify.
+	p := m.Proof(3)
+	p.Salt = ""
+	if p.Verify(blocks[3], m.Digest()) {
+		t.Error("Proof verified without its salt.")
+	}
+
+	// Bytes of the block cannot be moved into the salt.
+	p = m.Proof(3)
+	p.Salt += encode(blocks[3][:1])
+	if p.Verify(blocks[3][1:], m.Digest()) {
+		t.Error("Proof verified with a lengthened salt.")
+	}
+
+	// Appended and updated blocks are salted too.
+	a := m.Append([]byte("appended"))
+	u := a.Update(2, []byte("updated"))
+
+	if !u.Proof(7).Verify([]byte("appended"), u.Digest()) || !u.Proof(2).Verify([]byte("updated"), u.Digest()) {
+		t.Error("Changed block did not verify.")
+	}
+
+	if u.Proof(7).Salt == "" || u.Proof(2).Salt == "" {
+		t.Error("Changed block was not salted.")
+	}
+}
+
+func TestKeyedMerkle(t *testing.T) {
+	blocks := testBlocks(5)
+	key := []byte("secret")
+
+	m := NewKeyedMerkle(blocks, key)
+
+	if !m.Equal(NewKeyedMerkle(blocks, key)) {
+		t.Error("Keyed trees with the same key should be equal.")
+	}
+
+	if m.Equal(NewKeyedMerkle(blocks, []byte("other"))) {
+		t.Error("Keyed trees with different keys should not be equal.")
+	}
+
+	if m.Proof(4).Salt != encode(KeyedSalt(key, 4)) {
+		t.Error("Proof does not carry the keyed salt.")
+	}
+
+	for i := range blocks {
+		if !m.Proof(i).Verify(blocks[i], m.Digest()) {
+			t.Fatalf("Proof for block %d did not verify.", i)
+		}
+	}
+
+	// The key holder can keep appending with the keyed salts.
+	a := m.AppendSalted([]byte("block 5"), KeyedSalt(key, 5))
+	if !a.Equal(NewKeyedMerkle(testBlocks(6), key)) {
+		t.Error("Appending with a keyed salt gave the wrong digest.")
+	}
+
+	u := a.UpdateSalted(5, []byte("block 5"), KeyedSalt(key, 5))
+	if !u.Equal(a) || a.UpdateSalted(6, nil, nil) != nil {
+		t.Error("Updating with a keyed salt gave the wrong tree.")
+	}
+
+	// Append and Update salt new blocks with the key too.
+	changed := testBlocks(7)
+	changed[2] = []byte("changed")
+
+	k := m.Append(changed[5]).Append(changed[6]).Update(2, changed[2])
+	if !k.Equal(NewKeyedMerkle(changed, key)) {
+		t.Error("Appending and updating a keyed tree gave the wrong digest.")
+	}
+
+	if e := NewKeyedMerkle(nil, key).Append(blocks[0]); !e.Equal(NewKeyedMerkle(blocks[:1], key)) {
+		t.Error("Appending to an empty keyed tree gave the wrong digest.")
+	}
+
+	// Other salts would leave blocks the key cannot recreate.
+	if m.AppendSalted([]byte("block 5"), NewSalt()) != nil || m.UpdateSalted(1, []byte("block 1"), NewSalt()) != nil {
+		t.Error("Keyed tree took a salt not derived from its key.")
+	}
+}
+
+func TestSaltedPlainTree(t *testing.T) {
+	blocks := testBlocks(4)
+	m := NewMerkle(blocks)
+
+	// Salted leaves cannot join a Plain tree.
+	if m.AppendSalted([]byte("block 4"), NewSalt()) != nil || m.UpdateSalted(1, []byte("block 1"), NewSalt()) != nil {
+		t.Error("Plain tree took a salted block.")
+	}
+
+	// An empty tree can become a Salted one.
+	e := NewMerkle(nil).AppendSalted(blocks[0], NewSalt())
+	if e == nil || e.Scheme() != Salted {
+		t.Fatal("Expected a Salted tree from an empty one.")
+	}
+
+	e = e.AppendSalted(blocks[1], NewSalt()).Append(blocks[2])
+	if faults := e.Fsck(blocks[:3]); len(faults) != 0 {
+		t.Error("Salted tree has faults:", faults)
+	}
+}
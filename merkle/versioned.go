@@ -0,0 +1,201 @@
+package merkle
+
+import (
+	"crypto/hmac"
+)
+
+// Merkle trees are never modified once built. Append and Update return a new
+// root that shares every unchanged subtree with the tree they were called
+// on, so both versions stay valid for Digest, Diff and Proof.
+
+// Append returns a new Merkle tree with block added after the last block.
+// Only the nodes on the path to the new leaf are created. In a Salted tree
+// the block is hashed under a new random salt, or under its keyed salt in a
+// tree from NewKeyedMerkle.
+func (m *Merkle) Append(block []byte) *Merkle {
+	return m.addLeaf(m.newLeaf(m.nodes, block))
+}
+
+// AppendSalted returns a new Salted Merkle tree with block added after the
+// last block, hashed under the given salt. It returns nil if the tree is a
+// non-empty Plain tree, or if it is keyed and salt is not the keyed salt of
+// the new block.
+func (m *Merkle) AppendSalted(block, salt []byte) *Merkle {
+	if !m.acceptsSalt(m.nodes, salt) {
+		return nil
+	}
+
+	return m.addLeaf(newSaltedLeafNode(block, salt))
+}
+
+// addLeaf returns a new tree with leaf added after the last block. It keeps
+// the key of a keyed tree.
+func (m *Merkle) addLeaf(leaf *Merkle) *Merkle {
+	if m.nodes == 0 {
+		leaf.key = m.key
+		return leaf
+	}
+
+	n := appendLeaf(m, leaf)
+	n.key = m.key
+
+	return n
+}
+
+// newLeaf returns a new leaf node for the block at index using the scheme
+// of the tree.
+func (m *Merkle) newLeaf(index int, block []byte) *Merkle {
+	switch {
+	case m.key != nil:
+		return newSaltedLeafNode(block, KeyedSalt(m.key, index))
+	case m.scheme == Salted:
+		return newSaltedLeafNode(block, NewSalt())
+	}
+
+	return newLeafNode(block)
+}
+
+// acceptsSalt returns true if the block at index may be hashed under salt
+// without changing the scheme of the tree. Salted leaves may only start an
+// empty tree or join a Salted one, and a keyed tree only takes the salts
+// derived from its key.
+func (m *Merkle) acceptsSalt(index int, salt []byte) bool {
+	if m.nodes > 0 && m.scheme != Salted {
+		return false
+	}
+
+	return m.key == nil || hmac.Equal(salt, KeyedSalt(m.key, index))
+}
+
+// appendLeaf returns a copy of the subtree n with leaf added after its last
+// leaf.
+func appendLeaf(n, leaf *Merkle) *Merkle {
+	// A full subtree becomes the left half of a new node.
+	if n.nodes == 1<<uint(n.level) {
+		return newMerkleNode(n, raise(leaf, n.level))
+	}
+
+	// A node without a right child was carried up from its left child.
+	if n.right == nil {
+		if n.left.nodes == 1<<uint(n.left.level) {
+			return newMerkleNode(n.left, raise(leaf, n.left.level))
+		}
+
+		return newMerkleNode(appendLeaf(n.left, leaf), nil)
+	}
+
+	return newMerkleNode(n.left, appendLeaf(n.right, leaf))
+}
+
+// raise carries n up to the given level through nodes without a right child.
+func raise(n *Merkle, level int) *Merkle {
+	for n.level < level {
+		n = newMerkleNode(n, nil)
+	}
+
+	return n
+}
+
+// Update returns a new Merkle tree with the block at the given index
+// replaced. Only the nodes on the path to the replaced leaf are created.
+// Salted and keyed trees hash the block as Append does. It returns nil if
+// the index is out of range.
+func (m *Merkle) Update(index int, block []byte) *Merkle {
+	if index < 0 || index >= m.nodes {
+		return nil
+	}
+
+	n := replaceLeaf(m, index, m.newLeaf(index, block))
+	n.key = m.key
+
+	return n
+}
+
+// UpdateSalted returns a new Salted Merkle tree with the block at the given
+// index replaced and hashed under the given salt. It returns nil if the
+// index is out of range, or if the tree is Plain or keyed and would no
+// longer be, as AppendSalted does.
+func (m *Merkle) UpdateSalted(index int, block, salt []byte) *Merkle {
+	if index < 0 || index >= m.nodes || !m.acceptsSalt(index, salt) {
+		return nil
+	}
+
+	n := replaceLeaf(m, index, newSaltedLeafNode(block, salt))
+	n.key = m.key
+
+	return n
+}
+
+// replaceLeaf returns a copy of the subtree n with the leaf at index
+// replaced.
+func replaceLeaf(n *Merkle, index int, leaf *Merkle) *Merkle {
+	if n.left == nil {
+		return leaf
+	}
+
+	half := 1 << uint(n.level-1)
+
+	if index < half {
+		return newMerkleNode(replaceLeaf(n.left, index, leaf), n.right)
+	}
+
+	return newMerkleNode(n.left, replaceLeaf(n.right, index-half, leaf))
+}
+
+// History records every version of a Merkle tree. Versions share unchanged
+// subtrees, so each change costs only the nodes on one path from the root.
+type History struct {
+	versions []*Merkle
+}
+
+// Append adds block to the latest version and returns the new version
+// number.
+func (h *History) Append(block []byte) int {
+	return h.commit(h.Latest().Append(block))
+}
+
+// Update replaces the block at the given index in the latest version and
+// returns the new version number. It returns -1 if the index is out of
+// range.
+func (h *History) Update(index int, block []byte) int {
+	m := h.Latest().Update(index, block)
+	if m == nil {
+		return -1
+	}
+
+	return h.commit(m)
+}
+
+func (h *History) commit(m *Merkle) int {
+	h.versions = append(h.versions, m)
+
+	return len(h.versions) - 1
+}
+
+// Latest returns the most recent version of the tree.
+func (h *History) Latest() *Merkle {
+	return h.versions[len(h.versions)-1]
+}
+
+// Version returns the tree as it was at the given version, or nil if there
+// is no such version. The initial tree is version 0.
+func (h *History) Version(v int) *Merkle {
+	if v < 0 || v >= len(h.versions) {
+		return nil
+	}
+
+	return h.versions[v]
+}
+
+// Len returns the number of versions in the history.
+func (h *History) Len() int {
+	return len(h.versions)
+}
+
+// NewHistory returns a new History whose version 0 is the given tree.
+func NewHistory(m *Merkle) *History {
+	h := new(History)
+	h.versions = []*Merkle{m}
+
+	return h
+}
@@ -0,0 +1,95 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func TestAppend(t *testing.T) {
+	blocks := testBlocks(40)
+	m := NewMerkle(blocks[:1])
+
+	for i := 1; i < len(blocks); i++ {
+		m = m.Append(blocks[i])
+		expected := NewMerkle(blocks[:i+1])
+
+		if !m.Equal(expected) || m.String() != expected.String() {
+			t.Fatalf("Expected %s, received %s.", expected.String(), m.String())
+		}
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	for size := 1; size <= 20; size++ {
+		blocks := testBlocks(size)
+		m := NewMerkle(blocks)
+
+		for i := range blocks {
+			changed := testBlocks(size)
+			changed[i] = []byte("changed")
+
+			u := m.Update(i, changed[i])
+			if !u.Equal(NewMerkle(changed)) {
+				t.Fatalf("Update of block %d of %d produced the wrong digest.", i, size)
+			}
+		}
+
+		if m.Update(size, nil) != nil {
+			t.Error("Expected nil for an out of range update.")
+		}
+	}
+
+	// The updated tree shares the untouched half with the original.
+	m := NewMerkle(testBlocks(8))
+	u := m.Update(7, []byte("changed"))
+
+	if u.left != m.left || u.right == m.right {
+		t.Error("Update did not share the unchanged subtree.")
+	}
+}
+
+func TestHistory(t *testing.T) {
+	blocks := testBlocks(10)
+	h := NewHistory(NewMerkle(blocks[:1]))
+
+	for i := 1; i < len(blocks); i++ {
+		if v := h.Append(blocks[i]); v != i {
+			t.Fatal("Expected version ", i, "got", v)
+		}
+	}
+
+	v := h.Update(3, []byte("changed"))
+	if v != 10 || h.Len() != 11 {
+		t.Fatal("Expected version ", 10, "got", v)
+	}
+
+	if h.Update(10, nil) != -1 || h.Len() != 11 {
+		t.Error("Out of range update added a version.")
+	}
+
+	// Every old version still answers proofs.
+	for v := 0; v < 10; v++ {
+		old := h.Version(v)
+
+		if old.Blocks() != v+1 {
+			t.Fatal("Expected ", v+1, "blocks got", old.Blocks())
+		}
+
+		for i := 0; i <= v; i++ {
+			if !old.Proof(i).Verify(blocks[i], old.Digest()) {
+				t.Errorf("Proof for block %d of version %d did not verify.", i, v)
+			}
+		}
+	}
+
+	// And diffs between versions find the change.
+	var diffs []string
+	h.Version(9).Diff(h.Latest(), &diffs)
+
+	if len(diffs) != 1 {
+		t.Error("Expected ", 1, "got", len(diffs))
+	}
+
+	if h.Version(11) != nil || h.Version(-1) != nil {
+		t.Error("Expected nil for a missing version.")
+	}
+}
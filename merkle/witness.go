@@ -0,0 +1,320 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// Witness is an inclusion proof that can be brought up to date as blocks are
+// appended to the tree, without fetching a new proof. Frontier holds the hex
+// encoded digests of the largest full subtrees covering the blocks after
+// Proof.Index, left to right. Siblings to the left of the block never
+// change, and those to the right can be rebuilt from the frontier and the
+// appended blocks.
+type Witness struct {
+	Proof    *Proof   `json:"proof"`
+	Frontier []string `json:"frontier"`
+}
+
+// Consistency proves that the tree of OldSize blocks is a prefix of the tree
+// of NewSize blocks. Hashes holds the hex encoded digests of the largest
+// full subtrees covering the first OldSize blocks, left to right, followed
+// by those covering the remaining blocks.
+type Consistency struct {
+	OldSize int      `json:"old_size"`
+	NewSize int      `json:"new_size"`
+	Hashes  []string `json:"hashes"`
+}
+
+// Witness returns a witness for the block at the given index. It returns
+// nil if the index is out of range.
+func (m *Merkle) Witness(index int) *Witness {
+	p := m.Proof(index)
+	if p == nil {
+		return nil
+	}
+
+	return &Witness{Proof: p, Frontier: m.subtrees(compactRange(index+1, m.nodes))}
+}
+
+// Consistency returns a proof that the tree of the first old blocks is a
+// prefix of this tree. It returns nil if old is out of range.
+func (m *Merkle) Consistency(old int) *Consistency {
+	if old < 0 || old > m.nodes {
+		return nil
+	}
+
+	c := &Consistency{OldSize: old, NewSize: m.nodes}
+	c.Hashes = append(m.subtrees(compactRange(0, old)), m.subtrees(compactRange(old, m.nodes))...)
+
+	return c
+}
+
+// subtrees returns the hex encoded digests of the full subtrees.
+func (m *Merkle) subtrees(spans []span) []string {
+	hashes := make([]string, len(spans))
+
+	for i, s := range spans {
+		n, start := m, s.index<<uint(s.level)
+
+		for n.level > s.level {
+			half := 1 << uint(n.level-1)
+
+			if start < half {
+				n = n.left
+			} else {
+				n = n.right
+				start -= half
+			}
+		}
+
+		hashes[i] = n.encoded
+	}
+
+	return hashes
+}
+
+// Verify returns true if the proof shows that the tree with digest
+// newDigest extends the tree with digest oldDigest.
+func (c *Consistency) Verify(oldDigest, newDigest string) bool {
+	if c.OldSize < 0 || c.NewSize < c.OldSize {
+		return false
+	}
+
+	old := compactRange(0, c.OldSize)
+	added := compactRange(c.OldSize, c.NewSize)
+
+	if len(c.Hashes) != len(old)+len(added) {
+		return false
+	}
+
+	known := make(knownSubtrees)
+	if !known.add(old, c.Hashes[:len(old)]) || !known.add(added, c.Hashes[len(old):]) {
+		return false
+	}
+
+	return known.root(c.OldSize) == oldDigest && known.root(c.NewSize) == newDigest
+}
+
+// Verify returns true if the witness shows that block is stored at index
+// in the Merkle tree of size blocks with the given hex encoded digest, as
+// Proof.VerifyAt does.
+func (w *Witness) Verify(block []byte, index, size int, digest string) bool {
+	return w.Proof != nil && w.Proof.VerifyAt(block, index, size, digest)
+}
+
+// Update brings the witness up to date with blocks appended to a Plain tree.
+func (w *Witness) Update(blocks [][]byte) error {
+	leaves := make([]string, len(blocks))
+	for i := range blocks {
+		digest := sha256.Sum256(blocks[i])
+		leaves[i] = hex.EncodeToString(digest[:])
+	}
+
+	return w.UpdateLeaves(leaves)
+}
+
+// UpdateLeaves brings the witness up to date with leaves appended to the
+// tree, given as the hex encoded leaf digests returned by Leaves. Salted
+// trees must be updated this way since their leaves depend on the salts.
+func (w *Witness) UpdateLeaves(leaves []string) error {
+	if w.Proof == nil {
+		return ErrProof
+	}
+
+	spans := make([]span, len(leaves))
+	for i := range spans {
+		spans[i] = span{0, w.Proof.Size + i}
+	}
+
+	return w.extend(w.Proof.Size+len(leaves), spans, leaves)
+}
+
+// Apply brings the witness up to date using a consistency proof from the
+// witness's tree size. The consistency proof should be checked with Verify
+// first; the updated witness only verifies against the new digest if the
+// proof is genuine.
+func (w *Witness) Apply(c *Consistency) error {
+	if w.Proof == nil || c.OldSize != w.Proof.Size || c.NewSize < c.OldSize {
+		return ErrProof
+	}
+
+	old := len(compactRange(0, c.OldSize))
+	added := compactRange(c.OldSize, c.NewSize)
+
+	if len(c.Hashes) != old+len(added) {
+		return ErrProof
+	}
+
+	return w.extend(c.NewSize, added, c.Hashes[old:])
+}
+
+// extend updates the witness to a tree of size blocks, where the subtrees
+// in spans cover the blocks appended to the tree.
+func (w *Witness) extend(size int, spans []span, hashes []string) error {
+	p := w.Proof
+	if p.Index < 0 || p.Index >= p.Size {
+		return ErrProof
+	}
+
+	known := make(knownSubtrees)
+	if !known.add(compactRange(p.Index+1, p.Size), w.Frontier) || !known.add(spans, hashes) {
+		return ErrProof
+	}
+
+	// Keep the left siblings and check the right siblings against the
+	// frontier.
+	left := make(map[int]string)
+	siblings := p.Hashes
+	index, n := p.Index, p.Size
+
+	for level := 0; n > 1; level++ {
+		if index%2 == 1 || index+1 < n {
+			if len(siblings) == 0 {
+				return ErrProof
+			}
+
+			if index%2 == 1 {
+				left[level] = siblings[0]
+			} else if digest, ok := known.digest(level, index+1, p.Size); !ok || hex.EncodeToString(digest[:]) != siblings[0] {
+				return ErrProof
+			}
+
+			siblings = siblings[1:]
+		}
+
+		index /= 2
+		n = (n + 1) / 2
+	}
+
+	if len(siblings) != 0 {
+		return ErrProof
+	}
+
+	updated := &Proof{Index: p.Index, Size: size, Salt: p.Salt}
+	index, n = p.Index, size
+
+	for level := 0; n > 1; level++ {
+		if index%2 == 1 {
+			updated.Hashes = append(updated.Hashes, left[level])
+		} else if index+1 < n {
+			digest, ok := known.digest(level, index+1, size)
+			if !ok {
+				return ErrProof
+			}
+
+			updated.Hashes = append(updated.Hashes, hex.EncodeToString(digest[:]))
+		}
+
+		index /= 2
+		n = (n + 1) / 2
+	}
+
+	var frontier []string
+	for _, s := range compactRange(p.Index+1, size) {
+		digest, ok := known.digest(s.level, s.index, size)
+		if !ok {
+			return ErrProof
+		}
+
+		frontier = append(frontier, hex.EncodeToString(digest[:]))
+	}
+
+	w.Proof = updated
+	w.Frontier = frontier
+
+	return nil
+}
+
+// span identifies a full subtree by its level and its index within the
+// level.
+type span struct {
+	level int
+	index int
+}
+
+// compactRange returns the largest full subtrees that cover the blocks from
+// start up to end, left to right.
+func compactRange(start, end int) []span {
+	var spans []span
+
+	for start < end {
+		level := 0
+		for start%(2<<uint(level)) == 0 && start+(2<<uint(level)) <= end {
+			level++
+		}
+
+		spans = append(spans, span{level, start >> uint(level)})
+		start += 1 << uint(level)
+	}
+
+	return spans
+}
+
+// knownSubtrees holds the digests of full subtrees.
+type knownSubtrees map[span][32]byte
+
+// add decodes the hex encoded digests of the subtrees in spans. It returns
+// false if they do not match.
+func (k knownSubtrees) add(spans []span, hashes []string) bool {
+	if len(spans) != len(hashes) {
+		return false
+	}
+
+	for i := range spans {
+		digest, err := hex.DecodeString(hashes[i])
+		if err != nil || len(digest) != sha256.Size {
+			return false
+		}
+
+		k[spans[i]] = [32]byte(digest)
+	}
+
+	return true
+}
+
+// digest returns the digest of the node at the given level and index of a
+// tree of size blocks, built from the known subtrees. It returns false if
+// the subtrees do not cover the node.
+func (k knownSubtrees) digest(level, index, size int) ([32]byte, bool) {
+	if digest, ok := k[span{level, index}]; ok && (index+1)<<uint(level) <= size {
+		return digest, true
+	}
+
+	if level == 0 {
+		return [32]byte{}, false
+	}
+
+	// A node whose right child is past the last block is carried up.
+	left, ok := k.digest(level-1, 2*index, size)
+	if !ok || (2*index+1)<<uint(level-1) >= size {
+		return left, ok
+	}
+
+	right, ok := k.digest(level-1, 2*index+1, size)
+	if !ok {
+		return right, false
+	}
+
+	return sha256.Sum256(append(left[:], right[:]...)), true
+}
+
+// root returns the hex encoded root digest of a tree of size blocks, or an
+// empty string if the known subtrees do not cover it.
+func (k knownSubtrees) root(size int) string {
+	if size == 0 {
+		return EmptyDigest
+	}
+
+	level := 0
+	for 1<<uint(level) < size {
+		level++
+	}
+
+	digest, ok := k.digest(level, 0, size)
+	if !ok {
+		return ""
+	}
+
+	return hex.EncodeToString(digest[:])
+}
@@ -0,0 +1,147 @@
+package merkle
+
+import (
+	"testing"
+)
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalWitness(a, b *Witness) bool {
+	return a.Proof.Index == b.Proof.Index && a.Proof.Size == b.Proof.Size &&
+		a.Proof.Salt == b.Proof.Salt && equalStrings(a.Proof.Hashes, b.Proof.Hashes) &&
+		equalStrings(a.Frontier, b.Frontier)
+}
+
+func TestWitness(t *testing.T) {
+	const size = 20
+	blocks := testBlocks(size)
+
+	trees := make([]*Merkle, size+1)
+	for n := range trees {
+		trees[n] = NewMerkle(blocks[:n])
+	}
+
+	for n := 1; n <= size; n++ {
+		for m := n; m <= size; m++ {
+			for i := 0; i < n; i++ {
+				expected := trees[m].Witness(i)
+
+				w := trees[n].Witness(i)
+				if err := w.Update(blocks[n:m]); err != nil || !equalWitness(w, expected) {
+					t.Fatalf("Witness for %d updated from %d to %d does not match: %v", i, n, m, err)
+				}
+
+				if !w.Verify(blocks[i], i, m, trees[m].Digest()) {
+					t.Fatalf("Witness for %d updated from %d to %d did not verify.", i, n, m)
+				}
+
+				w = trees[n].Witness(i)
+				if err := w.Apply(trees[m].Consistency(n)); err != nil || !equalWitness(w, expected) {
+					t.Fatalf("Witness for %d applied from %d to %d does not match: %v", i, n, m, err)
+				}
+			}
+		}
+	}
+
+	// Updating in several steps gives the same witness.
+	w := trees[3].Witness(1)
+	for n := 3; n < size; n++ {
+		if err := w.Update(blocks[n : n+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !equalWitness(w, trees[size].Witness(1)) {
+		t.Error("Witness updated one block at a time does not match.")
+	}
+
+	// Salted trees are updated with their leaf digests.
+	salted := NewSaltedMerkle(blocks[:5])
+	w = salted.Witness(2)
+	for _, b := range blocks[5:] {
+		salted = salted.Append(b)
+	}
+
+	if err := w.UpdateLeaves(salted.Leaves()[5:]); err != nil || !w.Verify(blocks[2], 2, len(blocks), salted.Digest()) {
+		t.Error("Salted witness did not verify after update.", err)
+	}
+}
+
+func TestWitnessErrors(t *testing.T) {
+	blocks := testBlocks(10)
+	m := NewMerkle(blocks[:6])
+
+	if m.Witness(6) != nil || m.Witness(-1) != nil {
+		t.Error("Expected no witness for an index out of range.")
+	}
+
+	// A frontier that does not match the proof is rejected.
+	w := m.Witness(1)
+	w.Frontier[0] = m.Witness(4).Frontier[0]
+	if err := w.Update(blocks[6:]); err != ErrProof {
+		t.Error("Expected ", ErrProof, "got", err)
+	}
+
+	w = m.Witness(1)
+	w.Frontier = w.Frontier[1:]
+	if err := w.Update(blocks[6:]); err != ErrProof {
+		t.Error("Expected ", ErrProof, "got", err)
+	}
+
+	// The consistency proof must start at the witness's size.
+	w = m.Witness(1)
+	if err := w.Apply(NewMerkle(blocks).Consistency(5)); err != ErrProof {
+		t.Error("Expected ", ErrProof, "got", err)
+	}
+
+	// Appended leaves that do not match the tree give a witness that does
+	// not verify.
+	w = m.Witness(1)
+	if err := w.Update(testBlocks(10)[:4]); err != nil || w.Verify(blocks[1], 1, len(blocks), NewMerkle(blocks).Digest()) {
+		t.Error("Witness verified with the wrong appended blocks.")
+	}
+}
+
+func TestConsistency(t *testing.T) {
+	const size = 20
+	blocks := testBlocks(size)
+
+	for m := 0; m <= size; m++ {
+		tree := NewMerkle(blocks[:m])
+
+		for n := 0; n <= m; n++ {
+			old := NewMerkle(blocks[:n])
+			c := tree.Consistency(n)
+
+			if !c.Verify(old.Digest(), tree.Digest()) {
+				t.Fatalf("Consistency from %d to %d did not verify.", n, m)
+			}
+
+			if n > 0 && n < size && c.Verify(NewMerkle(blocks[1:n+1]).Digest(), tree.Digest()) {
+				t.Fatalf("Consistency from %d to %d verified for another tree.", n, m)
+			}
+		}
+
+		if tree.Consistency(m+1) != nil || tree.Consistency(-1) != nil {
+			t.Error("Expected no consistency proof for a size out of range.")
+		}
+	}
+
+	c := NewMerkle(blocks).Consistency(7)
+	c.Hashes = c.Hashes[1:]
+	if c.Verify(NewMerkle(blocks[:7]).Digest(), NewMerkle(blocks).Digest()) {
+		t.Error("Consistency verified with a hash missing.")
+	}
+}
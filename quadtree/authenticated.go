@@ -0,0 +1,248 @@
+package quadtree
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/asggo/structures/merkle"
+)
+
+// Encoder is the interface for the Encode method, which returns the
+// serialized form of a value. Values must implement it to be committed to
+// in an authenticated quadtree.
+type Encoder interface {
+	Encode() []byte
+}
+
+// Commitment is a Merkle commitment to the values of a Quadtree. Each node
+// is hashed over its bounds, a Merkle tree of its values and the hashes of
+// its four children. The bounds of a node are its box grown to cover every
+// value below it, since a value is placed by its center and may overhang
+// the node. Clients holding only the digest can check the answers to range
+// queries using the proofs it produces.
+type Commitment struct {
+	root *authNode
+}
+
+type authNode struct {
+	bounds   *Box
+	values   []ProofValue
+	children [4]*authNode
+	inner    [32]byte
+	digest   [32]byte
+}
+
+// ProofValue is a value in a range query proof, its box and serialized form.
+type ProofValue struct {
+	Box  [4]int `json:"box"`
+	Data []byte `json:"data"`
+}
+
+// ProofNode is a node in a range query proof. A node whose bounds intersect
+// the query holds all of its values and its children. Any other node is
+// pruned and holds only its bounds and the digest of its contents.
+type ProofNode struct {
+	Bounds   [4]int       `json:"bounds"`
+	Digest   string       `json:"digest,omitempty"`
+	Values   []ProofValue `json:"values,omitempty"`
+	Children []*ProofNode `json:"children,omitempty"`
+}
+
+// RangeProof proves the values that intersect a query box. Boxes are given
+// as the x and y of the bottom, left corner followed by the width and
+// height.
+type RangeProof struct {
+	Query [4]int     `json:"query"`
+	Root  *ProofNode `json:"root"`
+}
+
+// Commit returns a Merkle commitment to the current values of the Quadtree.
+// Later changes to the tree do not affect the commitment. It returns an
+// error if a value does not implement Encoder.
+func (n *Node) Commit() (*Commitment, error) {
+	root, err := commit(&n.node)
+	if err != nil {
+		return nil, err
+	}
+
+	c := new(Commitment)
+	c.root = root
+
+	return c, nil
+}
+
+func commit(n *node[int, *Box, Boxer]) (*authNode, error) {
+	a := new(authNode)
+	a.bounds = n.boundingBox
+
+	var blocks [][]byte
+	for _, v := range n.values {
+		e, ok := v.(Encoder)
+		if !ok {
+			return nil, fmt.Errorf("quadtree: value %v does not implement Encoder", v)
+		}
+
+		pv := ProofValue{Box: boxArray(v.Box()), Data: e.Encode()}
+		a.values = append(a.values, pv)
+		blocks = append(blocks, valueBlock(pv.Box, pv.Data))
+		a.bounds = a.bounds.union(v.Box())
+	}
+
+	var children []*[32]byte
+
+	if n.children[0] != nil {
+		for i := range n.children {
+			child, err := commit(n.children[i])
+			if err != nil {
+				return nil, err
+			}
+
+			a.children[i] = child
+			a.bounds = a.bounds.union(child.bounds)
+			children = append(children, &child.digest)
+		}
+	}
+
+	a.inner = innerDigest(blocks, children)
+	a.digest = nodeDigest(boxArray(a.bounds), a.inner)
+
+	return a, nil
+}
+
+// Digest returns the hex encoded digest of the commitment.
+func (c *Commitment) Digest() string {
+	return hex.EncodeToString(c.root.digest[:])
+}
+
+// Retrieve returns a proof of the values that intersect the given box.
+func (c *Commitment) Retrieve(b *Box) *RangeProof {
+	return &RangeProof{Query: boxArray(b), Root: c.root.prove(b)}
+}
+
+func (a *authNode) prove(b *Box) *ProofNode {
+	p := &ProofNode{Bounds: boxArray(a.bounds)}
+
+	if !a.bounds.Intersects(b) {
+		p.Digest = hex.EncodeToString(a.inner[:])
+		return p
+	}
+
+	p.Values = append(p.Values, a.values...)
+
+	if a.children[0] != nil {
+		for i := range a.children {
+			p.Children = append(p.Children, a.children[i].prove(b))
+		}
+	}
+
+	return p
+}
+
+// Verify checks that the proof answers the given query for the commitment
+// with the given digest. It returns the values that intersect the query and
+// true if the proof is valid. The query must be the one the client asked,
+// not one taken from the proof, or a proof for any other box would pass.
+// Every node whose bounds intersect the query must be expanded, so no
+// intersecting value can be left out.
+func (p *RangeProof) Verify(query *Box, digest string) ([]ProofValue, bool) {
+	if p.Root == nil || query == nil || p.Query != boxArray(query) {
+		return nil, false
+	}
+
+	var values []ProofValue
+
+	root, ok := p.Root.verify(query, &values)
+	if !ok || hex.EncodeToString(root[:]) != digest {
+		return nil, false
+	}
+
+	return values, true
+}
+
+func (p *ProofNode) verify(query *Box, values *[]ProofValue) ([32]byte, bool) {
+	bounds := arrayBox(p.Bounds)
+
+	if p.Digest != "" {
+		inner, err := hex.DecodeString(p.Digest)
+		if err != nil || len(inner) != sha256.Size || bounds.Intersects(query) || len(p.Values) != 0 || len(p.Children) != 0 {
+			return [32]byte{}, false
+		}
+
+		return nodeDigest(p.Bounds, [32]byte(inner)), true
+	}
+
+	if !bounds.Intersects(query) || (len(p.Children) != 0 && len(p.Children) != 4) {
+		return [32]byte{}, false
+	}
+
+	var blocks [][]byte
+	for _, v := range p.Values {
+		blocks = append(blocks, valueBlock(v.Box, v.Data))
+
+		if query.Intersects(arrayBox(v.Box)) {
+			*values = append(*values, v)
+		}
+	}
+
+	var children []*[32]byte
+	for _, child := range p.Children {
+		digest, ok := child.verify(query, values)
+		if !ok {
+			return [32]byte{}, false
+		}
+
+		children = append(children, &digest)
+	}
+
+	return nodeDigest(p.Bounds, innerDigest(blocks, children)), true
+}
+
+// appendBox appends the box as four 64 bit big endian integers.
+func appendBox(buf []byte, b [4]int) []byte {
+	for _, v := range b {
+		buf = binary.BigEndian.AppendUint64(buf, uint64(v))
+	}
+
+	return buf
+}
+
+// valueBlock returns the block committed for a value, the digest of its box
+// followed by its serialized form. Every block is 32 bytes, so no pair of
+// values can be passed off as the 64 bytes hashed into an interior node of
+// the Merkle tree.
+func valueBlock(box [4]int, data []byte) []byte {
+	digest := sha256.Sum256(append(appendBox(nil, box), data...))
+
+	return digest[:]
+}
+
+// innerDigest returns the digest of a node's contents: the root of the
+// Merkle tree of its values followed by the digests of its children, if it
+// has any.
+func innerDigest(blocks [][]byte, children []*[32]byte) [32]byte {
+	root, _ := hex.DecodeString(merkle.NewMerkle(blocks).Digest())
+
+	buf := append(root, byte(len(children)))
+	for _, c := range children {
+		buf = append(buf, c[:]...)
+	}
+
+	return sha256.Sum256(buf)
+}
+
+// nodeDigest returns the digest of a node from its bounds and contents.
+func nodeDigest(bounds [4]int, inner [32]byte) [32]byte {
+	return sha256.Sum256(append(appendBox(nil, bounds), inner[:]...))
+}
+
+// boxArray returns the x, y, width and height of the box.
+func boxArray(b *Box) [4]int {
+	return [4]int{b.x, b.y, b.width, b.height}
+}
+
+// arrayBox returns the box with the given x, y, width and height.
+func arrayBox(a [4]int) *Box {
+	return NewBox(a[0], a[1], a[2], a[3])
+}
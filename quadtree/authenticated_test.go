@@ -0,0 +1,204 @@
+package quadtree
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/asggo/structures/merkle"
+)
+
+func (o *Object) Encode() []byte {
+	return []byte(o.String())
+}
+
+type plain struct {
+	box *Box
+}
+
+func (p *plain) Box() *Box {
+	return p.box
+}
+
+func TestCommitment(t *testing.T) {
+	box := NewBox(-256, -256, 512, 512)
+	qt := NewNode(0, box)
+
+	var objects []*Object
+	for i := 0; i < 500; i++ {
+		o := randomObject(box)
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	c, err := qt.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digest := c.Digest()
+
+	// Later changes to the tree do not change the commitment.
+	qt.Insert(randomObject(box))
+	if c2, _ := qt.Commit(); c2.Digest() == digest {
+		t.Error("Commitment did not change with the tree.")
+	}
+
+	for i := 0; i < 50; i++ {
+		query := NewBox(rand.Intn(480)-256, rand.Intn(480)-256, rand.Intn(100)+1, rand.Intn(100)+1)
+
+		// Objects may share a position, so count each one.
+		expected := make(map[string]int)
+		count := 0
+		for _, o := range objects {
+			if query.Intersects(o.Box()) {
+				expected[o.String()]++
+				count++
+			}
+		}
+
+		values, ok := c.Retrieve(query).Verify(query, digest)
+		if !ok {
+			t.Fatal("Proof did not verify for", query)
+		}
+
+		if len(values) != count {
+			t.Error("Expected ", count, "got", len(values))
+		}
+
+		for _, v := range values {
+			if expected[string(v.Data)] == 0 {
+				t.Error("Unexpected value", string(v.Data))
+			}
+			expected[string(v.Data)]--
+		}
+	}
+
+	// The whole plane returns every value.
+	if values, ok := c.Retrieve(box).Verify(box, digest); !ok || len(values) != len(objects) {
+		t.Error("Expected ", len(objects), "got", len(values))
+	}
+}
+
+func TestCommitmentTampering(t *testing.T) {
+	box := NewBox(-64, -64, 128, 128)
+	qt := NewNode(0, box)
+
+	for i := 0; i < 100; i++ {
+		qt.Insert(randomObject(box))
+	}
+
+	c, _ := qt.Commit()
+	digest := c.Digest()
+	query := NewBox(-20, -20, 30, 30)
+
+	// expanded returns a node of the proof that holds values.
+	var expanded func(p *ProofNode) *ProofNode
+	expanded = func(p *ProofNode) *ProofNode {
+		if len(p.Values) > 0 {
+			return p
+		}
+
+		for _, child := range p.Children {
+			if e := expanded(child); e != nil {
+				return e
+			}
+		}
+
+		return nil
+	}
+
+	p := c.Retrieve(query)
+	e := expanded(p.Root)
+	e.Values = e.Values[1:]
+	if _, ok := p.Verify(query, digest); ok {
+		t.Error("Proof verified with a value left out.")
+	}
+
+	p = c.Retrieve(query)
+	e = expanded(p.Root)
+	e.Values[0].Data = []byte("[(0, 0), 4]")
+	if _, ok := p.Verify(query, digest); ok {
+		t.Error("Proof verified with a changed value.")
+	}
+
+	// Pruning a node that intersects the query hides its values.
+	p = c.Retrieve(query)
+	pruned := c.Retrieve(NewBox(1000, 1000, 1, 1))
+	p.Root = pruned.Root
+	if _, ok := p.Verify(query, digest); ok {
+		t.Error("Proof verified with an intersecting node pruned.")
+	}
+
+	// The query is covered by the proof.
+	p = c.Retrieve(query)
+	p.Query = [4]int{-64, -64, 128, 128}
+	if _, ok := p.Verify(query, digest); ok {
+		t.Error("Proof verified for a larger query.")
+	}
+
+	// A whole proof for another box does not answer the query, even though
+	// it is a valid proof against the digest.
+	other := NewBox(1000, 1000, 1, 1)
+	if _, ok := c.Retrieve(other).Verify(other, digest); !ok {
+		t.Error("Proof did not verify for its own query.")
+	}
+
+	if _, ok := c.Retrieve(other).Verify(query, digest); ok {
+		t.Error("Proof for another box verified for the query.")
+	}
+
+	if _, ok := c.Retrieve(query).Verify(nil, digest); ok {
+		t.Error("Proof verified without a query.")
+	}
+
+	if _, ok := c.Retrieve(query).Verify(query, fmt.Sprintf("%064d", 0)); ok {
+		t.Error("Proof verified against another commitment.")
+	}
+
+	qt.Insert(&plain{NewBox(0, 0, 2, 2)})
+	if _, err := qt.Commit(); err == nil {
+		t.Error("Expected an error for a value without Encode.")
+	}
+}
+
+func TestCommitmentCollapsedValues(t *testing.T) {
+	qt := NewNode(0, NewBox(-128, -128, 256, 256))
+	qt.Insert(&Object{x: 10, y: 10, r: 2})
+	qt.Insert(&Object{x: 20, y: 20, r: 2})
+	qt.Insert(&Object{x: 100, y: 100, r: 2})
+
+	c, _ := qt.Commit()
+	digest := c.Digest()
+	query := NewBox(0, 0, 50, 50)
+
+	p := c.Retrieve(query)
+	if values, ok := p.Verify(query, digest); !ok || len(values) != 2 {
+		t.Fatal("Expected ", 2, "got", len(values))
+	}
+
+	// Replace the first two values with one whose box and data are the
+	// digests of their leaves, which would hash to their parent in the
+	// Merkle tree of values if blocks could be 64 bytes.
+	var blocks [][]byte
+	for _, v := range p.Root.Values {
+		blocks = append(blocks, valueBlock(v.Box, v.Data))
+	}
+
+	leaves := merkle.NewMerkle(blocks).Leaves()
+	first, _ := hex.DecodeString(leaves[0])
+	second, _ := hex.DecodeString(leaves[1])
+
+	var forged ProofValue
+	for i := range forged.Box {
+		forged.Box[i] = int(binary.BigEndian.Uint64(first[8*i:]))
+	}
+	forged.Data = second
+
+	p.Root.Values = append([]ProofValue{forged}, p.Root.Values[2:]...)
+	if values, ok := p.Verify(query, digest); ok {
+		t.Error("Proof verified with two values collapsed into one, returning", len(values))
+	}
+}
@@ -0,0 +1,43 @@
+package quadtree
+
+// coordinate is the constraint for the coordinates of a box.
+type coordinate interface {
+	~int | ~float64
+}
+
+// bounds is the interface for the boxes a Quadtree is built from, *Box with
+// int coordinates and *FloatBox with float64 coordinates. The tree is
+// written once against it, so both kinds of box get every feature.
+type bounds[C coordinate, B any] interface {
+	String() string
+	Left() C
+	Right() C
+	Top() C
+	Bottom() C
+	CenterX() C
+	CenterY() C
+	Quarter() [4]B
+	Contains(B) bool
+	ContainsBox(B) bool
+	ContainsCenter(B) bool
+	Intersects(B) bool
+
+	// union returns the smallest box containing both boxes.
+	union(B) B
+
+	// loosen returns the box grown on every side by f times its width and
+	// height.
+	loosen(f float64) B
+
+	// quarterSize returns the width and height of the quarters of the box,
+	// and false if the box is too small to be quartered.
+	quarterSize() (float64, float64, bool)
+
+	// distance returns the distance from the point (x, y) to the box.
+	distance(x, y C) float64
+
+	// double returns the box doubled in size, growing toward the left and
+	// bottom if the flags are set and toward the right and top otherwise.
+	// It returns false if the box cannot be doubled.
+	double(left, down bool) (B, bool)
+}
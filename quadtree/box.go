@@ -2,6 +2,7 @@ package quadtree
 
 import (
 	"fmt"
+	"math"
 )
 
 // Box defines an axis-aligned bounding box using the (x,y) value of the
@@ -80,6 +81,56 @@ func (b *Box) Intersects(c *Box) bool {
 	return xIntersect && yIntersect
 }
 
+// union returns the smallest box containing both boxes.
+func (b *Box) union(c *Box) *Box {
+	left, bottom := min(b.Left(), c.Left()), min(b.Bottom(), c.Bottom())
+	right, top := max(b.Right(), c.Right()), max(b.Top(), c.Top())
+
+	return NewBox(left, bottom, right-left, top-bottom)
+}
+
+// loosen returns the box grown on every side by f times its width and
+// height.
+func (b *Box) loosen(f float64) *Box {
+	dx := int(f * float64(b.width))
+	dy := int(f * float64(b.height))
+
+	return NewBox(b.x-dx, b.y-dy, b.width+2*dx, b.height+2*dy)
+}
+
+// quarterSize returns the width and height of the quarters of the box.
+func (b *Box) quarterSize() (float64, float64, bool) {
+	return float64(b.width / 2), float64(b.height / 2), true
+}
+
+// distance returns the distance from the point (x, y) to the box.
+func (b *Box) distance(x, y int) float64 {
+	dx := max(b.Left()-x, 0, x-b.Right())
+	dy := max(b.Bottom()-y, 0, y-b.Top())
+
+	return math.Hypot(float64(dx), float64(dy))
+}
+
+// double returns the box doubled in size toward the left and bottom if the
+// flags are set. It returns false if the box has no size or its double would
+// overflow.
+func (b *Box) double(left, down bool) (*Box, bool) {
+	if b.width <= 0 || b.height <= 0 || b.width > math.MaxInt/4 || b.height > math.MaxInt/4 {
+		return nil, false
+	}
+
+	x, y := b.x, b.y
+	if left {
+		x -= b.width
+	}
+
+	if down {
+		y -= b.height
+	}
+
+	return NewBox(x, y, 2*b.width, 2*b.height), true
+}
+
 // NewBox creates a new Box structure.
 func NewBox(x, y, width, height int) *Box {
 	b := new(Box)
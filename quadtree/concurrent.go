@@ -0,0 +1,303 @@
+package quadtree
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrentQuadTree is a QuadTree that is safe for concurrent use. Writes
+// are serialized with each other and copy the nodes they change rather than
+// changing them in place, so readers take snapshots without locking and may
+// keep using them while the tree is updated.
+type ConcurrentQuadTree[T ComparableBoxer] struct {
+	concurrent[int, *Box, T]
+}
+
+// ConcurrentFloatQuadTree is a FloatQuadTree that is safe for concurrent
+// use in the same way as ConcurrentQuadTree.
+type ConcurrentFloatQuadTree[T ComparableFloatBoxer] struct {
+	concurrent[float64, *FloatBox, T]
+}
+
+// Snapshot is an immutable view of a ConcurrentQuadTree at one point in
+// time.
+type Snapshot[T ComparableBoxer] struct {
+	snapshot[int, *Box, T]
+}
+
+// FloatSnapshot is an immutable view of a ConcurrentFloatQuadTree at one
+// point in time.
+type FloatSnapshot[T ComparableFloatBoxer] struct {
+	snapshot[float64, *FloatBox, T]
+}
+
+// concurrent is the tree that ConcurrentQuadTree and
+// ConcurrentFloatQuadTree are built on.
+type concurrent[C coordinate, B bounds[C, B], T comparable] struct {
+	mu      sync.Mutex
+	current atomic.Pointer[tree[C, B, T]]
+}
+
+// snapshot is the view that Snapshot and FloatSnapshot are built on. Only
+// the methods that read the tree are forwarded.
+type snapshot[C coordinate, B bounds[C, B], T comparable] struct {
+	tree *tree[C, B, T]
+}
+
+// Snapshot returns the current state of the tree.
+func (c *ConcurrentQuadTree[T]) Snapshot() *Snapshot[T] {
+	s := new(Snapshot[T])
+	s.tree = c.current.Load()
+
+	return s
+}
+
+// Snapshot returns the current state of the tree.
+func (c *ConcurrentFloatQuadTree[T]) Snapshot() *FloatSnapshot[T] {
+	s := new(FloatSnapshot[T])
+	s.tree = c.current.Load()
+
+	return s
+}
+
+// Insert adds the value to the tree. It returns false if the value is
+// rejected, as QuadTree.Insert does.
+func (c *concurrent[C, B, T]) Insert(v T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	q, ok := c.insert(c.current.Load(), v)
+	if ok {
+		c.current.Store(q)
+	}
+
+	return ok
+}
+
+// insert returns a copy of the tree with the value added.
+func (c *concurrent[C, B, T]) insert(old *tree[C, B, T], v T) (*tree[C, B, T], bool) {
+	q := &tree[C, B, T]{root: old.root, len: old.len}
+	b := q.root.box(v)
+
+	if !q.root.boundingBox.ContainsCenter(b) {
+		if !q.root.options.Grow {
+			return nil, false
+		}
+
+		// Growing changes the level of every node, so the whole tree is
+		// copied. The root doubles each time, so this is rare.
+		q.root = q.root.deepCopy()
+
+		for !q.root.boundingBox.ContainsCenter(b) {
+			if !q.grow(b) {
+				return nil, false
+			}
+		}
+	}
+
+	q.root = q.root.insertCopy(v)
+	q.len++
+
+	return q, true
+}
+
+// Remove removes the value from the tree. It returns false if the value was
+// not found.
+func (c *concurrent[C, B, T]) Remove(v T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old := c.current.Load()
+
+	root, ok := old.root.removeCopy(v, old.root.box(v))
+	if ok {
+		c.current.Store(&tree[C, B, T]{root: root, len: old.len - 1})
+	}
+
+	return ok
+}
+
+// Update replaces the value old with new in a single write. Values in a
+// snapshot may be in use by readers, so they must never be changed in
+// place; a moved value is a new value put in place of the old one. Readers
+// see either old or new, never both or neither. Update returns false and
+// leaves the tree unchanged if old is not found or new cannot be inserted.
+func (c *concurrent[C, B, T]) Update(old, new T) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.current.Load()
+
+	root, ok := current.root.removeCopy(old, current.root.box(old))
+	if !ok {
+		return false
+	}
+
+	q, ok := c.insert(&tree[C, B, T]{root: root, len: current.len - 1}, new)
+	if ok {
+		c.current.Store(q)
+	}
+
+	return ok
+}
+
+// Clear removes every value from the tree.
+func (c *concurrent[C, B, T]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old := c.current.Load()
+	c.current.Store(&tree[C, B, T]{root: newNode[C](0, old.root.boundingBox, old.root.options, old.root.box)})
+}
+
+// clone returns a copy of the node that shares its children. The values are
+// copied so that appending to them does not change the original.
+func (n *node[C, B, V]) clone() *node[C, B, V] {
+	c := new(node[C, B, V])
+	*c = *n
+	c.values = append([]V(nil), n.values...)
+
+	return c
+}
+
+// deepCopy returns a copy of the node and every node below it.
+func (n *node[C, B, V]) deepCopy() *node[C, B, V] {
+	c := n.clone()
+
+	if c.children[0] != nil {
+		for i := range c.children {
+			c.children[i] = c.children[i].deepCopy()
+		}
+	}
+
+	return c
+}
+
+// insertCopy returns a copy of the node with the value inserted, as Insert
+// would. Only the nodes on the path to the value are copied.
+func (n *node[C, B, V]) insertCopy(v V) *node[C, B, V] {
+	if !n.boundingBox.ContainsCenter(n.box(v)) {
+		return n
+	}
+
+	c := n.clone()
+	c.extent = c.extent.union(c.box(v))
+
+	// A new split only creates new nodes, so Insert can be used on them.
+	if i := c.index(c.box(v)); i == -1 {
+		c.values = append(c.values, v)
+
+		if len(c.values) > c.options.Capacity && c.children[0] == nil && c.canSplit() {
+			c.split()
+		}
+	} else {
+		c.children[i] = c.children[i].insertCopy(v)
+	}
+
+	return c
+}
+
+// removeCopy returns a copy of the node with the value removed, as remove
+// would. Only the nodes on the path to the value are copied. It returns
+// false if the value was not found.
+func (n *node[C, B, V]) removeCopy(v V, b B) (*node[C, B, V], bool) {
+	if !n.boundingBox.ContainsCenter(b) {
+		return n, false
+	}
+
+	for i := range n.values {
+		if n.values[i] == v {
+			c := n.clone()
+			c.values = append(c.values[:i], c.values[i+1:]...)
+			c.collapse()
+			c.resize()
+
+			return c, true
+		}
+	}
+
+	if n.children[0] == nil {
+		return n, false
+	}
+
+	for i := range n.children {
+		if child, ok := n.children[i].removeCopy(v, b); ok {
+			c := n.clone()
+			c.children[i] = child
+			c.collapse()
+			c.resize()
+
+			return c, true
+		}
+	}
+
+	return n, false
+}
+
+// Len returns the number of values in the snapshot.
+func (s *snapshot[C, B, T]) Len() int {
+	return s.tree.Len()
+}
+
+// Bounds returns the box covered by the snapshot.
+func (s *snapshot[C, B, T]) Bounds() B {
+	return s.tree.Bounds()
+}
+
+// Stats walks the snapshot and returns a report of its shape.
+func (s *snapshot[C, B, T]) Stats() Stats {
+	return s.tree.Stats()
+}
+
+// Query returns all values whose boxes intersect the shape.
+func (s *snapshot[C, B, T]) Query(shape shape[B]) []T {
+	return s.tree.Query(shape)
+}
+
+// QueryFunc returns all values whose boxes intersect the shape and for
+// which filter returns true.
+func (s *snapshot[C, B, T]) QueryFunc(shape shape[B], filter func(T) bool) []T {
+	return s.tree.QueryFunc(shape, filter)
+}
+
+// Nearest returns up to k values closest to the point (x, y), closest
+// first.
+func (s *snapshot[C, B, T]) Nearest(x, y C, k int) []T {
+	return s.tree.Nearest(x, y, k)
+}
+
+// NearestWithin returns up to k values closest to the point (x, y) that are
+// no further than maxDist from it, closest first.
+func (s *snapshot[C, B, T]) NearestWithin(x, y C, k int, maxDist float64) []T {
+	return s.tree.NearestWithin(x, y, k, maxDist)
+}
+
+// All returns an iterator over every value in the snapshot.
+func (s *snapshot[C, B, T]) All() iter.Seq[T] {
+	return s.tree.All()
+}
+
+// Search returns an iterator over the values whose boxes intersect the
+// shape.
+func (s *snapshot[C, B, T]) Search(shape shape[B]) iter.Seq[T] {
+	return s.tree.Search(shape)
+}
+
+// NewConcurrentQuadTree creates a new ConcurrentQuadTree covering the given
+// box with nodes that split as the options say.
+func NewConcurrentQuadTree[T ComparableBoxer](box *Box, opts Options) *ConcurrentQuadTree[T] {
+	c := new(ConcurrentQuadTree[T])
+	c.current.Store(&NewQuadTreeWithOptions[T](box, opts).tree)
+
+	return c
+}
+
+// NewConcurrentFloatQuadTree creates a new ConcurrentFloatQuadTree covering
+// the given box with nodes that split as the options say.
+func NewConcurrentFloatQuadTree[T ComparableFloatBoxer](box *FloatBox, opts Options) *ConcurrentFloatQuadTree[T] {
+	c := new(ConcurrentFloatQuadTree[T])
+	c.current.Store(&NewFloatQuadTreeWithOptions[T](box, opts).tree)
+
+	return c
+}
@@ -0,0 +1,303 @@
+package quadtree
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConcurrentQuadTree(t *testing.T) {
+	box := NewBox(-128, -128, 256, 256)
+	qt := NewConcurrentQuadTree[*Object](box, Options{Capacity: 4})
+
+	var objects []*Object
+	for i := 0; i < 200; i++ {
+		o := randomObject(box)
+		objects = append(objects, o)
+
+		if !qt.Insert(o) {
+			t.Error("Failed to insert", o)
+		}
+	}
+
+	if qt.Insert(&Object{x: 500, y: 500, r: 1}) {
+		t.Error("Inserted a value outside of the tree.")
+	}
+
+	before := qt.Snapshot()
+
+	for _, o := range objects[:100] {
+		if !qt.Remove(o) {
+			t.Error("Failed to remove", o)
+		}
+	}
+
+	if qt.Remove(objects[0]) {
+		t.Error("Removed a value twice.")
+	}
+
+	// The snapshot taken before the removals still holds every value.
+	if before.Len() != len(objects) || len(before.Query(box)) != len(objects) {
+		t.Error("Expected ", len(objects), "got", len(before.Query(box)))
+	}
+
+	after := qt.Snapshot()
+	if after.Len() != 100 || len(after.Query(box)) != 100 {
+		t.Error("Expected ", 100, "got", len(after.Query(box)))
+	}
+
+	count := 0
+	for range after.All() {
+		count++
+	}
+
+	if count != 100 || after.Stats().Values != 100 {
+		t.Error("Expected ", 100, "got", count)
+	}
+
+	o := objects[150]
+	moved := &Object{x: -100, y: -100, r: o.r}
+
+	if !qt.Update(o, moved) {
+		t.Error("Failed to update", o)
+	}
+
+	if qt.Update(o, moved) {
+		t.Error("Updated a value that was already replaced.")
+	}
+
+	if qt.Update(moved, &Object{x: 500, y: 500, r: 1}) {
+		t.Error("Updated a value to outside of the tree.")
+	}
+
+	found := false
+	for _, v := range qt.Snapshot().Query(NewBox(-110, -110, 20, 20)) {
+		found = found || v == moved
+	}
+
+	if !found || qt.Snapshot().Len() != 100 {
+		t.Error("Updated value not found at its new box.")
+	}
+
+	count = 0
+	for v := range after.All() {
+		if v == o {
+			count++
+		}
+
+		if v == moved {
+			t.Error("Update changed an earlier snapshot.")
+		}
+	}
+
+	if count != 1 || after.Len() != 100 || o.x == -100 {
+		t.Error("Update changed an earlier snapshot.")
+	}
+
+	qt.Clear()
+	if qt.Snapshot().Len() != 0 || len(qt.Snapshot().Query(box)) != 0 {
+		t.Error("Expected an empty tree after Clear.")
+	}
+
+	if after.Len() != 100 {
+		t.Error("Clear changed an earlier snapshot.")
+	}
+}
+
+func TestConcurrentQuadTreeGrow(t *testing.T) {
+	qt := NewConcurrentQuadTree[*Object](NewBox(0, 0, 64, 64), Options{Capacity: 4, Grow: true})
+
+	for i := 0; i < 50; i++ {
+		qt.Insert(randomObject(NewBox(0, 0, 64, 64)))
+	}
+
+	before := qt.Snapshot()
+	stats := before.Stats()
+
+	if !qt.Insert(&Object{x: 500, y: 500, r: 1}) {
+		t.Error("Failed to grow the tree.")
+	}
+
+	if before.Bounds().width != 64 || before.Stats().Depth != stats.Depth {
+		t.Error("Growing changed an earlier snapshot.")
+	}
+
+	after := qt.Snapshot()
+	if after.Len() != 51 || !after.Bounds().ContainsCenter(NewBox(500, 500, 0, 0)) {
+		t.Error("Expected ", 51, "got", after.Len())
+	}
+}
+
+func TestConcurrentFloatQuadTree(t *testing.T) {
+	qt := NewConcurrentFloatQuadTree[*floatObject](NewFloatBox(0, 0, 1, 1), Options{Capacity: 4, Grow: true})
+
+	var objects []*floatObject
+	for i := 0; i < 100; i++ {
+		o := &floatObject{x: rand.Float64(), y: rand.Float64(), r: 0.01}
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	before := qt.Snapshot()
+
+	if !qt.Insert(&floatObject{x: 10.5, y: -3.25}) {
+		t.Error("Failed to grow the tree.")
+	}
+
+	for _, o := range objects[:50] {
+		if !qt.Remove(o) {
+			t.Error("Failed to remove", o)
+		}
+	}
+
+	if before.Len() != 100 || len(before.Query(NewFloatCircle(0.5, 0.5, 1))) != 100 || before.Bounds().width != 1 {
+		t.Error("Changes reached an earlier snapshot.")
+	}
+
+	after := qt.Snapshot()
+	if after.Len() != 51 || len(after.Query(after.Bounds())) != 51 {
+		t.Error("Expected ", 51, "got", after.Len())
+	}
+}
+
+func TestConcurrentQuadTreeRace(t *testing.T) {
+	box := NewBox(-256, -256, 512, 512)
+	qt := NewConcurrentQuadTree[*Object](box, Options{Capacity: 4})
+
+	var wg sync.WaitGroup
+	var done atomic.Bool
+
+	// Each writer inserts its own values, moves some of them and removes
+	// the rest.
+	writers := 4
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var objects []*Object
+			for i := 0; i < 200; i++ {
+				o := randomObject(box)
+				objects = append(objects, o)
+				qt.Insert(o)
+			}
+
+			for _, o := range objects[:50] {
+				if !qt.Update(o, &Object{x: -o.x, y: o.y, r: o.r}) {
+					t.Error("Failed to update", o)
+				}
+			}
+
+			for _, o := range objects[50:100] {
+				qt.Remove(o)
+			}
+		}()
+	}
+
+	// Readers check that every snapshot agrees with itself.
+	var readers sync.WaitGroup
+	for r := 0; r < 4; r++ {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+
+			for !done.Load() {
+				s := qt.Snapshot()
+
+				count := 0
+				for range s.All() {
+					count++
+				}
+
+				if count != s.Len() || len(s.Query(box)) != s.Len() {
+					t.Error("Expected ", s.Len(), "got", count)
+					return
+				}
+
+				x, y := rand.Intn(512)-256, rand.Intn(512)-256
+				s.Nearest(x, y, 5)
+				s.Query(NewCircle(x, y, 40))
+			}
+		}()
+	}
+
+	wg.Wait()
+	done.Store(true)
+	readers.Wait()
+
+	if s := qt.Snapshot(); s.Len() != writers*150 || len(s.Query(box)) != writers*150 {
+		t.Error("Expected ", writers*150, "got", s.Len())
+	}
+}
+
+// lockedQuadTree is a QuadTree guarded by a single RWMutex, to compare with
+// ConcurrentQuadTree.
+type lockedQuadTree struct {
+	sync.RWMutex
+	tree *QuadTree[*Object]
+}
+
+func benchmarkObjects(box *Box, n int) []*Object {
+	objects := make([]*Object, n)
+	for i := range objects {
+		objects[i] = randomObject(box)
+	}
+
+	return objects
+}
+
+// Each benchmark runs reads and writes in parallel, with one write for
+// every writeEvery operations.
+const writeEvery = 10
+
+func BenchmarkConcurrentQuadTree(b *testing.B) {
+	box := NewBox(-1024, -1024, 2048, 2048)
+	qt := NewConcurrentQuadTree[*Object](box, Options{})
+
+	for _, o := range benchmarkObjects(box, 10000) {
+		qt.Insert(o)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for i := 0; pb.Next(); i++ {
+			if i%writeEvery == 0 {
+				o := randomObject(box)
+				qt.Insert(o)
+				qt.Remove(o)
+			} else {
+				qt.Snapshot().Query(NewBox(r.Intn(1900)-1024, r.Intn(1900)-1024, 100, 100))
+			}
+		}
+	})
+}
+
+func BenchmarkLockedQuadTree(b *testing.B) {
+	box := NewBox(-1024, -1024, 2048, 2048)
+	qt := &lockedQuadTree{tree: NewQuadTree[*Object](box)}
+
+	for _, o := range benchmarkObjects(box, 10000) {
+		qt.tree.Insert(o)
+	}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		r := rand.New(rand.NewSource(rand.Int63()))
+		for i := 0; pb.Next(); i++ {
+			if i%writeEvery == 0 {
+				o := randomObject(box)
+				qt.Lock()
+				qt.tree.Insert(o)
+				qt.tree.Remove(o)
+				qt.Unlock()
+			} else {
+				q := NewBox(r.Intn(1900)-1024, r.Intn(1900)-1024, 100, 100)
+				qt.RLock()
+				qt.tree.Query(q)
+				qt.RUnlock()
+			}
+		}
+	})
+}
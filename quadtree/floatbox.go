@@ -0,0 +1,176 @@
+package quadtree
+
+import (
+	"fmt"
+	"math"
+)
+
+// FloatBox defines an axis-aligned bounding box with float64 coordinates
+// using the (x,y) value of the bottom, left corner along with the width and
+// height of the box. A FloatBox is half-open: it holds the points from its
+// left side up to but not including its right side, and from its bottom up
+// to but not including its top. The quarters of a box therefore hold every
+// point of the box exactly once.
+type FloatBox struct {
+	x      float64
+	y      float64
+	width  float64
+	height float64
+}
+
+func (b *FloatBox) String() string {
+	return fmt.Sprintf("[%g, %g), [%g, %g)", b.Left(), b.Right(), b.Bottom(), b.Top())
+}
+
+// Left returns the x-value of the left side of the box.
+func (b *FloatBox) Left() float64 { return b.x }
+
+// Right returns the x-value of the right side of the box.
+func (b *FloatBox) Right() float64 { return b.x + b.width }
+
+// Top returns the y-value of the top side of the box.
+func (b *FloatBox) Top() float64 { return b.y + b.height }
+
+// Bottom returns the y-value of the bottom side of the box.
+func (b *FloatBox) Bottom() float64 { return b.y }
+
+// CenterX returns the x value of the center of the box
+func (b *FloatBox) CenterX() float64 {
+	return b.x + b.width/2
+}
+
+// CenterY returns the y value of the center of the box
+func (b *FloatBox) CenterY() float64 {
+	return b.y + b.height/2
+}
+
+// Quarter splits a box into its four quadrants starting at the top right
+// quadrant and going counter-clockwise.
+func (b *FloatBox) Quarter() [4]*FloatBox {
+	var quarters [4]*FloatBox
+
+	// The right and top quarters take whatever rounding leaves so the
+	// quarters meet the sides of the box exactly.
+	cx, cy := b.CenterX(), b.CenterY()
+
+	quarters[0] = NewFloatBox(cx, cy, b.Right()-cx, b.Top()-cy)                 // Top Right
+	quarters[1] = NewFloatBox(b.Left(), cy, cx-b.Left(), b.Top()-cy)            // Top Left
+	quarters[2] = NewFloatBox(b.Left(), b.Bottom(), cx-b.Left(), cy-b.Bottom()) // Bottom Left
+	quarters[3] = NewFloatBox(cx, b.Bottom(), b.Right()-cx, cy-b.Bottom())      // Bottom Right
+
+	return quarters
+}
+
+// ContainsPoint returns true if the point is inside this box.
+func (b *FloatBox) ContainsPoint(x, y float64) bool {
+	return b.Left() <= x && x < b.Right() && b.Bottom() <= y && y < b.Top()
+}
+
+// Contains returns true if the given box is fully contained by this box.
+func (b *FloatBox) Contains(c *FloatBox) bool {
+	x := (b.Left() <= c.Left()) && (b.Right() >= c.Right())
+	y := (b.Top() >= c.Top()) && (b.Bottom() <= c.Bottom())
+
+	return x && y
+}
+
+// ContainsCenter returns true if the center of the given box is contained by
+// this box.
+func (b *FloatBox) ContainsCenter(c *FloatBox) bool {
+	return b.ContainsPoint(c.CenterX(), c.CenterY())
+}
+
+// Intersects returns true if the give box overlaps this box. Boxes that only
+// share an edge do not overlap, but a box with no width or height is treated
+// as a line or point and overlaps any box that holds it.
+func (b *FloatBox) Intersects(c *FloatBox) bool {
+	xIntersect := overlaps(b.Left(), b.Right(), c.Left(), c.Right())
+	yIntersect := overlaps(b.Bottom(), b.Top(), c.Bottom(), c.Top())
+
+	return xIntersect && yIntersect
+}
+
+// overlaps returns true if the half-open intervals [lo1, hi1) and [lo2, hi2)
+// overlap. An empty interval is treated as the single point at its start.
+func overlaps(lo1, hi1, lo2, hi2 float64) bool {
+	switch {
+	case lo1 == hi1 && lo2 == hi2:
+		return lo1 == lo2
+	case lo2 == hi2:
+		return lo1 <= lo2 && lo2 < hi1
+	case lo1 == hi1:
+		return lo2 <= lo1 && lo1 < hi2
+	}
+
+	return lo1 < hi2 && lo2 < hi1
+}
+
+// ContainsBox returns true if the given box is fully contained by this box.
+// It is the same as Contains and lets a FloatBox be used as a FloatShape.
+func (b *FloatBox) ContainsBox(c *FloatBox) bool {
+	return b.Contains(c)
+}
+
+// union returns the smallest box containing both boxes.
+func (b *FloatBox) union(c *FloatBox) *FloatBox {
+	left, bottom := min(b.Left(), c.Left()), min(b.Bottom(), c.Bottom())
+	right, top := max(b.Right(), c.Right()), max(b.Top(), c.Top())
+
+	return NewFloatBox(left, bottom, right-left, top-bottom)
+}
+
+// loosen returns the box grown on every side by f times its width and
+// height.
+func (b *FloatBox) loosen(f float64) *FloatBox {
+	dx, dy := f*b.width, f*b.height
+
+	return NewFloatBox(b.x-dx, b.y-dy, b.width+2*dx, b.height+2*dy)
+}
+
+// quarterSize returns the width and height of the quarters of the box. A
+// box too small to be halved in float64 cannot be quartered.
+func (b *FloatBox) quarterSize() (float64, float64, bool) {
+	cx, cy := b.CenterX(), b.CenterY()
+
+	return cx - b.Left(), cy - b.Bottom(), cx < b.Right() && cy < b.Top()
+}
+
+// distance returns the distance from the point (x, y) to the box.
+func (b *FloatBox) distance(x, y float64) float64 {
+	dx := max(b.Left()-x, 0, x-b.Right())
+	dy := max(b.Bottom()-y, 0, y-b.Top())
+
+	return math.Hypot(dx, dy)
+}
+
+// double returns the box doubled in size toward the left and bottom if the
+// flags are set. It returns false if the box has no size or its double would
+// overflow.
+func (b *FloatBox) double(left, down bool) (*FloatBox, bool) {
+	if !(b.width > 0 && b.height > 0) || b.width > math.MaxFloat64/4 || b.height > math.MaxFloat64/4 {
+		return nil, false
+	}
+
+	x, y := b.x, b.y
+	if left {
+		x -= b.width
+	}
+
+	if down {
+		y -= b.height
+	}
+
+	return NewFloatBox(x, y, 2*b.width, 2*b.height), true
+}
+
+// NewFloatBox creates a new FloatBox structure.
+func NewFloatBox(x, y, width, height float64) *FloatBox {
+	b := new(FloatBox)
+
+	b.x = x
+	b.y = y
+	b.width = width
+	b.height = height
+
+	return b
+}
@@ -0,0 +1,55 @@
+package quadtree
+
+import (
+	"testing"
+)
+
+func TestFloatBox(t *testing.T) {
+	b := NewFloatBox(0, 0, 3, 5)
+
+	if b.CenterX() != 1.5 || b.CenterY() != 2.5 {
+		t.Error("Expected ", "(1.5, 2.5)", "got", b.CenterX(), b.CenterY())
+	}
+
+	// The box holds its left and bottom sides but not its right and top.
+	if !b.ContainsPoint(0, 0) || b.ContainsPoint(3, 1) || b.ContainsPoint(1, 5) {
+		t.Error("Box is not half-open.")
+	}
+
+	// Every point of the box is in exactly one quarter.
+	quarters := b.Quarter()
+	for _, p := range [][2]float64{{0, 0}, {1.5, 2.5}, {1.5, 0}, {0, 2.5}, {2.9, 4.9}, {1.49, 2.49}} {
+		count := 0
+		for _, q := range quarters {
+			if q.ContainsPoint(p[0], p[1]) {
+				count++
+			}
+		}
+
+		if count != 1 {
+			t.Error("Expected ", 1, "got", count, "for", p)
+		}
+	}
+
+	if quarters[0].Right() != b.Right() || quarters[0].Top() != b.Top() {
+		t.Error("Quarters do not reach the sides of the box.")
+	}
+
+	// Boxes sharing an edge do not intersect, but a point on the left edge
+	// is inside.
+	if b.Intersects(NewFloatBox(3, 0, 1, 1)) || !b.Intersects(NewFloatBox(2.5, 4.5, 1, 1)) {
+		t.Error("Boxes intersected wrongly.")
+	}
+
+	if !b.Intersects(NewFloatBox(0, 1, 0, 0)) || b.Intersects(NewFloatBox(3, 1, 0, 0)) {
+		t.Error("Points intersected wrongly.")
+	}
+
+	if !NewFloatBox(1, 1, 0, 0).Intersects(NewFloatBox(1, 1, 0, 0)) {
+		t.Error("Point should intersect itself.")
+	}
+
+	if !b.Contains(NewFloatBox(1, 1, 2, 4)) || b.Contains(NewFloatBox(1, 1, 2.5, 1)) {
+		t.Error("Box contained wrongly.")
+	}
+}
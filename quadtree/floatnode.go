@@ -0,0 +1,29 @@
+package quadtree
+
+// FloatBoxer is the interface for the FloatBox method.
+type FloatBoxer interface {
+	FloatBox() *FloatBox
+}
+
+// FloatNode defines a single node in a Quadtree with float64 coordinates. It
+// works as Node does, except that its boxes are half-open so that every
+// point belongs to exactly one child of a node.
+type FloatNode struct {
+	node[float64, *FloatBox, FloatBoxer]
+}
+
+// NewFloatNode creates a new Quadtree node with float64 coordinates and the
+// default options.
+func NewFloatNode(level int, box *FloatBox) *FloatNode {
+	return NewFloatNodeWithOptions(level, box, Options{})
+}
+
+// NewFloatNodeWithOptions creates a new Quadtree node with float64
+// coordinates that splits as the options say. Its children share the
+// options.
+func NewFloatNodeWithOptions(level int, box *FloatBox, opts Options) *FloatNode {
+	n := new(FloatNode)
+	n.node = *newNode[float64](level, box, opts.withDefaults(), FloatBoxer.FloatBox)
+
+	return n
+}
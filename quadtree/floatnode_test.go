@@ -0,0 +1,92 @@
+package quadtree
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+type floatObject struct {
+	x float64
+	y float64
+	r float64
+}
+
+func (o *floatObject) FloatBox() *FloatBox {
+	return NewFloatBox(o.x-o.r, o.y-o.r, o.r*2, o.r*2)
+}
+
+func TestFloatNode(t *testing.T) {
+	box := NewFloatBox(-1, -1, 2, 2)
+	qt := NewFloatNode(0, box)
+
+	var objects []*floatObject
+	for i := 0; i < 500; i++ {
+		o := &floatObject{x: rand.Float64()*2 - 1, y: rand.Float64()*2 - 1, r: rand.Float64() * 0.01}
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	// Points on the lines that split the box go in exactly one node.
+	for _, p := range [][2]float64{{0, 0}, {-1, -1}, {0, 0.5}, {-0.5, 0}} {
+		o := &floatObject{x: p[0], y: p[1]}
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	// The top and right sides are outside of the tree.
+	qt.Insert(&floatObject{x: 1, y: 0})
+	qt.Insert(&floatObject{x: 0, y: 1})
+
+	var count int
+	qt.Count(&count)
+
+	if count != len(objects) {
+		t.Error("Expected ", len(objects), "got", count)
+	}
+
+	for i := 0; i < 20; i++ {
+		query := NewFloatBox(rand.Float64()*2-1, rand.Float64()*2-1, rand.Float64()*0.5, rand.Float64()*0.5)
+
+		expected := 0
+		for _, o := range objects {
+			if query.Intersects(o.FloatBox()) {
+				expected++
+			}
+		}
+
+		var values []FloatBoxer
+		qt.Retrieve(query, &values)
+
+		if len(values) != expected {
+			t.Error("Expected ", expected, "got", len(values))
+		}
+
+		x, y := rand.Float64()*2-1, rand.Float64()*2-1
+
+		var distances []float64
+		for _, o := range objects {
+			distances = append(distances, o.FloatBox().distance(x, y))
+		}
+		sort.Float64s(distances)
+
+		for j, v := range qt.Nearest(x, y, 5) {
+			if d := v.FloatBox().distance(x, y); d != distances[j] {
+				t.Error("Expected ", distances[j], "got", d)
+			}
+		}
+	}
+
+	for _, o := range objects {
+		if !qt.Remove(o) {
+			t.Error("Failed to remove", o)
+		}
+	}
+
+	var nodes int
+	qt.NodeCount(&nodes)
+
+	if nodes != 1 {
+		t.Error("Expected ", 1, "got", nodes)
+	}
+}
@@ -0,0 +1,189 @@
+package quadtree
+
+// ComparableBoxer is the constraint for the values of a QuadTree. Values
+// are found for removal with ==, so they must be comparable.
+type ComparableBoxer interface {
+	Boxer
+	comparable
+}
+
+// ComparableFloatBoxer is the constraint for the values of a FloatQuadTree.
+// Values are found for removal with ==, so they must be comparable.
+type ComparableFloatBoxer interface {
+	FloatBoxer
+	comparable
+}
+
+// QuadTree is a type safe Quadtree holding values of type T. It is built on
+// the same nodes as Node, so values come back as T without type assertions.
+type QuadTree[T ComparableBoxer] struct {
+	tree[int, *Box, T]
+}
+
+// FloatQuadTree is a QuadTree with float64 coordinates, built on the same
+// nodes as FloatNode.
+type FloatQuadTree[T ComparableFloatBoxer] struct {
+	tree[float64, *FloatBox, T]
+}
+
+// tree is a Quadtree holding values of type T that QuadTree and
+// FloatQuadTree are built on.
+type tree[C coordinate, B bounds[C, B], T comparable] struct {
+	root *node[C, B, T]
+	len  int
+}
+
+// Len returns the number of values in the QuadTree.
+func (q *tree[C, B, T]) Len() int {
+	return q.len
+}
+
+// Bounds returns the box covered by the QuadTree, which changes as a
+// growing QuadTree grows. Boxes cannot be changed, so it is safe to share.
+func (q *tree[C, B, T]) Bounds() B {
+	return q.root.boundingBox
+}
+
+// Depth returns the level of the deepest node, with the root at level 0.
+func (q *tree[C, B, T]) Depth() int {
+	return q.Stats().Depth
+}
+
+// Insert adds the value to the QuadTree. If the center of the value's box is
+// outside of the QuadTree it is grown to hold it when the Grow option is
+// set. Otherwise, or if the tree cannot grow any further, the value is
+// rejected and Insert returns false.
+func (q *tree[C, B, T]) Insert(v T) bool {
+	for !q.root.boundingBox.ContainsCenter(q.root.box(v)) {
+		if !q.root.options.Grow || !q.grow(q.root.box(v)) {
+			return false
+		}
+	}
+
+	q.root.Insert(v)
+	q.len++
+
+	return true
+}
+
+// grow doubles the root toward the center of the given box, making the old
+// root one of the quarters of the new one. It returns false if the root
+// cannot be doubled.
+func (q *tree[C, B, T]) grow(b B) bool {
+	old := q.root.boundingBox
+	left, down := b.CenterX() < old.Left(), b.CenterY() < old.Bottom()
+
+	box, ok := old.double(left, down)
+	if !ok {
+		return false
+	}
+
+	// The old root is the quarter on the other side from the way the box
+	// grew. Quarters go counter-clockwise from the top right.
+	at := 2
+	switch {
+	case left && down:
+		at = 0
+	case down:
+		at = 1
+	case left:
+		at = 3
+	}
+
+	root := newNode[C](0, box, q.root.options, q.root.box)
+	q.root.deepen()
+
+	for i, quad := range root.boundingBox.Quarter() {
+		if i == at {
+			root.children[i] = q.root
+		} else {
+			root.children[i] = newNode[C](1, quad, root.options, root.box)
+		}
+	}
+
+	root.resize()
+	q.root = root
+
+	return true
+}
+
+// Remove removes the value from the QuadTree. It returns false if the value
+// was not found.
+func (q *tree[C, B, T]) Remove(v T) bool {
+	if !q.root.Remove(v) {
+		return false
+	}
+
+	q.len--
+
+	return true
+}
+
+// Update moves a value that was inserted with the box oldBox to the place
+// for its current box. A value that can no longer be inserted is removed.
+func (q *tree[C, B, T]) Update(v T, oldBox B) {
+	if q.root.remove(v, oldBox) {
+		q.len--
+		q.Insert(v)
+	}
+}
+
+// Clear removes every value from the QuadTree.
+func (q *tree[C, B, T]) Clear() {
+	q.root.Clear()
+	q.len = 0
+}
+
+// Query returns all values whose boxes intersect the shape.
+func (q *tree[C, B, T]) Query(s shape[B]) []T {
+	return q.QueryFunc(s, nil)
+}
+
+// QueryFunc returns all values whose boxes intersect the shape and for
+// which filter returns true. A nil filter accepts every value.
+func (q *tree[C, B, T]) QueryFunc(s shape[B], filter func(T) bool) []T {
+	var values []T
+	q.root.SearchFunc(s, filter, &values)
+
+	return values
+}
+
+// Nearest returns up to k values closest to the point (x, y), closest
+// first.
+func (q *tree[C, B, T]) Nearest(x, y C, k int) []T {
+	return q.root.Nearest(x, y, k)
+}
+
+// NearestWithin returns up to k values closest to the point (x, y) that are
+// no further than maxDist from it, closest first.
+func (q *tree[C, B, T]) NearestWithin(x, y C, k int, maxDist float64) []T {
+	return q.root.NearestWithin(x, y, k, maxDist)
+}
+
+// NewQuadTree creates a new QuadTree covering the given box.
+func NewQuadTree[T ComparableBoxer](box *Box) *QuadTree[T] {
+	return NewQuadTreeWithOptions[T](box, Options{})
+}
+
+// NewQuadTreeWithOptions creates a new QuadTree covering the given box with
+// nodes that split as the options say.
+func NewQuadTreeWithOptions[T ComparableBoxer](box *Box, opts Options) *QuadTree[T] {
+	q := new(QuadTree[T])
+	q.root = newNode[int](0, box, opts.withDefaults(), T.Box)
+
+	return q
+}
+
+// NewFloatQuadTree creates a new FloatQuadTree covering the given box.
+func NewFloatQuadTree[T ComparableFloatBoxer](box *FloatBox) *FloatQuadTree[T] {
+	return NewFloatQuadTreeWithOptions[T](box, Options{})
+}
+
+// NewFloatQuadTreeWithOptions creates a new FloatQuadTree covering the given
+// box with nodes that split as the options say.
+func NewFloatQuadTreeWithOptions[T ComparableFloatBoxer](box *FloatBox, opts Options) *FloatQuadTree[T] {
+	q := new(FloatQuadTree[T])
+	q.root = newNode[float64](0, box, opts.withDefaults(), T.FloatBox)
+
+	return q
+}
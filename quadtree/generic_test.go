@@ -0,0 +1,214 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGenericQuadTree(t *testing.T) {
+	box := NewBox(-128, -128, 256, 256)
+	qt := NewQuadTree[*Object](box)
+
+	var objects []*Object
+	for i := 0; i < 200; i++ {
+		o := randomObject(box)
+		objects = append(objects, o)
+
+		if !qt.Insert(o) {
+			t.Error("Failed to insert", o)
+		}
+	}
+
+	if qt.Insert(&Object{x: 500, y: 500, r: 1}) {
+		t.Error("Inserted a value outside of the tree.")
+	}
+
+	all := qt.Query(box)
+	if len(all) != len(objects) {
+		t.Error("Expected ", len(objects), "got", len(all))
+	}
+
+	right := qt.QueryFunc(box, func(o *Object) bool { return o.x > 0 })
+	for _, o := range right {
+		if o.x <= 0 {
+			t.Error("Filter not applied to", o)
+		}
+	}
+
+	nearest := qt.Nearest(0, 0, 3)
+	if len(nearest) != 3 || nearest[0].Box().distance(0, 0) > nearest[2].Box().distance(0, 0) {
+		t.Error("Expected the 3 nearest values in order, got", nearest)
+	}
+
+	if len(qt.NearestWithin(0, 0, 3, -1)) != 0 {
+		t.Error("Expected no values within a negative distance.")
+	}
+
+	if !qt.Remove(objects[0]) || qt.Remove(objects[0]) {
+		t.Error("Expected to remove a value once.")
+	}
+
+	old := objects[1].Box()
+	objects[1].x, objects[1].y = 120, 120
+	qt.Update(objects[1], old)
+
+	found := qt.Query(NewBox(110, 110, 20, 20))
+	if len(found) == 0 {
+		t.Error("Moved value not found.")
+	}
+
+	qt.Clear()
+	if len(qt.Query(box)) != 0 {
+		t.Error("Expected no values after clearing.")
+	}
+}
+
+func TestGrowingQuadTree(t *testing.T) {
+	qt := NewQuadTreeWithOptions[*Object](NewBox(0, 0, 16, 16), Options{Grow: true})
+
+	var objects []*Object
+	for i := 0; i < 100; i++ {
+		o := randomObject(NewBox(0, 0, 64, 64))
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	// Values far outside in every direction grow the root.
+	for _, p := range [][2]int{{-1000, 5}, {5, -1000}, {3000, 3000}, {-5000, -7000}, {8, 8}} {
+		o := &Object{x: p[0], y: p[1], r: 1}
+		objects = append(objects, o)
+
+		if !qt.Insert(o) {
+			t.Error("Failed to insert", o)
+		}
+	}
+
+	root := qt.root.boundingBox
+	for _, o := range objects {
+		if !root.ContainsCenter(o.Box()) {
+			t.Error("Root", root, "does not hold", o)
+		}
+	}
+
+	if root.width != root.height || root.width%16 != 0 {
+		t.Error("Expected the root to be a doubling of 16, got", root)
+	}
+
+	if qt.root.level != 0 || depth(qt.root) < 2 {
+		t.Error("Expected the old root below the new one.")
+	}
+
+	if all := qt.Query(root); len(all) != len(objects) {
+		t.Error("Expected ", len(objects), "got", len(all))
+	}
+
+	if n := qt.Nearest(3000, 3000, 1); len(n) != 1 || n[0] != objects[102] {
+		t.Error("Expected ", objects[102], "got", n)
+	}
+
+	for _, o := range objects {
+		if !qt.Remove(o) {
+			t.Error("Failed to remove", o)
+		}
+	}
+
+	if all := qt.Query(root); len(all) != 0 {
+		t.Error("Expected ", 0, "got", len(all))
+	}
+}
+
+// cell is a value type rather than a pointer. Values equal with == are the
+// same value to a QuadTree.
+type cell struct {
+	x int
+	y int
+}
+
+func (c cell) Box() *Box {
+	return NewBox(c.x, c.y, 1, 1)
+}
+
+func TestComparableValues(t *testing.T) {
+	qt := NewQuadTreeWithOptions[cell](NewBox(0, 0, 64, 64), Options{Capacity: 4})
+
+	for x := 0; x < 64; x += 8 {
+		for y := 0; y < 64; y += 8 {
+			qt.Insert(cell{x, y})
+		}
+	}
+
+	if !qt.Remove(cell{16, 24}) || qt.Remove(cell{16, 24}) {
+		t.Error("Expected an equal cell to be removed once.")
+	}
+
+	if qt.Len() != 63 || len(qt.Query(NewBox(16, 24, 1, 1))) != 0 {
+		t.Error("Expected ", 63, "got", qt.Len())
+	}
+}
+
+func TestFloatQuadTree(t *testing.T) {
+	qt := NewFloatQuadTreeWithOptions[*floatObject](NewFloatBox(0, 0, 1, 1), Options{Capacity: 4, Grow: true})
+
+	var objects []*floatObject
+	for i := 0; i < 300; i++ {
+		o := &floatObject{x: rand.Float64()*4 - 2, y: rand.Float64()*4 - 2, r: rand.Float64() * 0.05}
+		objects = append(objects, o)
+
+		if !qt.Insert(o) {
+			t.Error("Failed to insert", o)
+		}
+	}
+
+	root := qt.Bounds()
+	for _, o := range objects {
+		if !root.ContainsCenter(o.FloatBox()) {
+			t.Error("Root", root, "does not hold", o)
+		}
+	}
+
+	if s := qt.Stats(); s.Values != len(objects) || qt.Len() != len(objects) || s.Depth < 2 {
+		t.Error("Expected ", len(objects), "got", s.Values, qt.Len(), s.Depth)
+	}
+
+	shapes := []FloatShape{
+		NewFloatBox(-1, -1, 1.5, 0.5),
+		NewFloatCircle(0.5, -0.5, 0.75),
+		NewFloatPolygon([]FloatPoint{{-2, -2}, {2, -2}, {0, 2}}),
+	}
+
+	for _, s := range shapes {
+		expected := 0
+		for _, o := range objects {
+			if s.Intersects(o.FloatBox()) {
+				expected++
+			}
+		}
+
+		if n := len(qt.Query(s)); n != expected {
+			t.Error("Expected ", expected, "got", n)
+		}
+
+		n := 0
+		for range qt.Search(s) {
+			n++
+		}
+
+		if n != expected {
+			t.Error("Expected ", expected, "got", n)
+		}
+	}
+
+	if n := qt.Nearest(objects[7].x, objects[7].y, 1); len(n) != 1 || n[0].FloatBox().distance(objects[7].x, objects[7].y) != 0 {
+		t.Error("Expected a value at", objects[7], "got", n)
+	}
+
+	for _, o := range objects {
+		if !qt.Remove(o) {
+			t.Error("Failed to remove", o)
+		}
+	}
+
+	if qt.Len() != 0 || qt.Stats().Nodes != 1 {
+		t.Error("Expected an empty root, got", qt.Stats())
+	}
+}
@@ -0,0 +1,88 @@
+package quadtree
+
+import (
+	"container/heap"
+	"math"
+)
+
+// Nearest returns up to k values closest to the point (x, y), closest
+// first. The distance to a value is the distance from the point to the
+// nearest edge of its box, or zero if the point is inside the box.
+func (n *node[C, B, V]) Nearest(x, y C, k int) []V {
+	return n.NearestWithin(x, y, k, math.Inf(1))
+}
+
+// NearestWithin returns up to k values closest to the point (x, y) that are
+// no further than maxDist from it, closest first.
+//
+// Nodes are visited best first. A node's distance is measured to a box
+// covering every value below it, which is never further than the values
+// themselves, so once k values are closer than every unvisited node the
+// search stops.
+func (n *node[C, B, V]) NearestWithin(x, y C, k int, maxDist float64) []V {
+	if k <= 0 {
+		return nil
+	}
+
+	var values []V
+	q := &queue[node[C, B, V], V]{{distance: n.extent.distance(x, y), node: n}}
+
+	for q.Len() > 0 && len(values) < k {
+		e := heap.Pop(q).(entry[node[C, B, V], V])
+
+		if e.distance > maxDist {
+			break
+		}
+
+		if e.node == nil {
+			values = append(values, e.value)
+			continue
+		}
+
+		for _, v := range e.node.values {
+			heap.Push(q, entry[node[C, B, V], V]{distance: n.box(v).distance(x, y), value: v})
+		}
+
+		if e.node.children[0] != nil {
+			for _, c := range e.node.children {
+				heap.Push(q, entry[node[C, B, V], V]{distance: c.extent.distance(x, y), node: c})
+			}
+		}
+	}
+
+	return values
+}
+
+// entry is a node or value waiting to be visited by a nearest neighbor
+// search.
+type entry[N, V any] struct {
+	distance float64
+	node     *N
+	value    V
+}
+
+// queue is a priority queue of entries ordered by distance. Values come
+// before nodes at the same distance.
+type queue[N, V any] []entry[N, V]
+
+func (q queue[N, V]) Len() int { return len(q) }
+
+func (q queue[N, V]) Less(i, j int) bool {
+	if q[i].distance != q[j].distance {
+		return q[i].distance < q[j].distance
+	}
+
+	return q[i].node == nil && q[j].node != nil
+}
+
+func (q queue[N, V]) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queue[N, V]) Push(x interface{}) { *q = append(*q, x.(entry[N, V])) }
+
+func (q *queue[N, V]) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return e
+}
@@ -0,0 +1,79 @@
+package quadtree
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestNearest(t *testing.T) {
+	box := NewBox(-256, -256, 512, 512)
+	qt := NewNode(0, box)
+
+	var objects []*Object
+	for i := 0; i < 500; i++ {
+		o := randomObject(box)
+		o.r = rand.Intn(8) + 1
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	for i := 0; i < 50; i++ {
+		x, y := rand.Intn(600)-300, rand.Intn(600)-300
+
+		var expected []float64
+		for _, o := range objects {
+			expected = append(expected, o.Box().distance(x, y))
+		}
+		sort.Float64s(expected)
+
+		nearest := qt.Nearest(x, y, 5)
+		if len(nearest) != 5 {
+			t.Fatal("Expected ", 5, "got", len(nearest))
+		}
+
+		for j, v := range nearest {
+			if d := v.Box().distance(x, y); d != expected[j] {
+				t.Error("Expected ", expected[j], "got", d)
+			}
+		}
+
+		// Only values within the distance are returned.
+		within := qt.NearestWithin(x, y, 500, expected[10])
+		count := sort.SearchFloat64s(expected, math.Nextafter(expected[10], math.Inf(1)))
+
+		if len(within) != count {
+			t.Error("Expected ", count, "got", len(within))
+		}
+	}
+
+	if len(qt.Nearest(0, 0, 1000)) != len(objects) {
+		t.Error("Expected every value when k is larger than the tree.")
+	}
+
+	if qt.Nearest(0, 0, 0) != nil {
+		t.Error("Expected no values for k of 0.")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	b := NewBox(0, 0, 10, 10)
+
+	tests := []struct {
+		x, y     int
+		distance float64
+	}{
+		{5, 5, 0},
+		{10, 10, 0},
+		{13, 5, 3},
+		{-3, -4, 5},
+		{5, 12, 2},
+	}
+
+	for _, test := range tests {
+		if d := b.distance(test.x, test.y); d != test.distance {
+			t.Error("Expected ", test.distance, "got", d)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package quadtree
+
+// The default maximum depth of a Quadtree. It is deep enough for any int
+// box and stops a float64 box being split down to nothing.
+const defaultMaxDepth = 64
+
+// Options controls when the nodes of a Quadtree are split. Zero fields take
+// their defaults.
+type Options struct {
+	// Capacity is the number of values a node holds before it is split.
+	// The default is 16. A node's children are collapsed back into it when
+	// they hold no more than half of Capacity between them.
+	Capacity int
+
+	// MaxDepth is the level below which nodes are not split. Nodes at this
+	// level hold every value that reaches them, however many. The default
+	// is 64.
+	MaxDepth int
+
+	// MinSize is the smallest width or height of a child node. A node is
+	// not split if its quarters would be smaller. Nodes are never split
+	// into quarters with no width or height, whatever MinSize is.
+	MinSize float64
+
+	// Looseness makes a loose Quadtree. Each node has a loose box, its
+	// bounding box grown on every side by Looseness times its width and
+	// height, and a value is placed in the child holding its center only
+	// if the value fits in that child's loose box. Otherwise it stays in
+	// the parent. Large values then sit at the level that suits their size
+	// rather than overhanging small nodes. A Looseness of 0.5 gives loose
+	// boxes twice the size of the bounding boxes. The default of 0 places
+	// values by their centers alone.
+	Looseness float64
+
+	// Grow lets a QuadTree grow to hold values outside of its box. The
+	// root is doubled in size toward the value, with the old root as one
+	// of its children, until it holds the value's center. Nodes ignore it.
+	Grow bool
+}
+
+// withDefaults returns a copy of the options with zero fields set to their
+// defaults.
+func (o Options) withDefaults() *Options {
+	if o.Capacity <= 0 {
+		o.Capacity = maxNodeSize
+	}
+
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = defaultMaxDepth
+	}
+
+	return &o
+}
+
+// canSplit returns true if a node at the given level whose quarters have
+// the given width and height may be split.
+func (o *Options) canSplit(level int, width, height float64) bool {
+	return level < o.MaxDepth && width > 0 && height > 0 && width >= o.MinSize && height >= o.MinSize
+}
@@ -0,0 +1,178 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// depth returns the deepest level below the node.
+func depth[C coordinate, B bounds[C, B], V comparable](n *node[C, B, V]) int {
+	d := n.level
+	if n.children[0] != nil {
+		for _, c := range n.children {
+			d = max(d, depth(c))
+		}
+	}
+
+	return d
+}
+
+func TestSameCenter(t *testing.T) {
+	box := NewBox(-32, -32, 64, 64)
+
+	// Values sharing a center used to split until the boxes had no size.
+	qt := NewNode(0, box)
+	for i := 0; i < 100; i++ {
+		qt.Insert(&Object{x: 3, y: 3, r: 1})
+	}
+
+	var count int
+	qt.Count(&count)
+
+	if count != 100 {
+		t.Error("Expected ", 100, "got", count)
+	}
+
+	if d := depth(&qt.node); d > 6 {
+		t.Error("Expected a depth of at most", 6, "got", d)
+	}
+
+	fqt := NewFloatNode(0, NewFloatBox(-1, -1, 2, 2))
+	for i := 0; i < 100; i++ {
+		fqt.Insert(&floatObject{x: 0.1, y: 0.1})
+	}
+
+	count = 0
+	fqt.Count(&count)
+
+	if count != 100 {
+		t.Error("Expected ", 100, "got", count)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	box := NewBox(-32, -32, 64, 64)
+
+	qt := NewNodeWithOptions(0, box, Options{Capacity: 4, MaxDepth: 2})
+	for i := 0; i < 100; i++ {
+		qt.Insert(randomObject(box))
+	}
+
+	if d := depth(&qt.node); d != 2 {
+		t.Error("Expected ", 2, "got", d)
+	}
+
+	// Nodes are split once they hold more than the capacity.
+	qt = NewNodeWithOptions(0, box, Options{Capacity: 4})
+	for i := 0; i < 5; i++ {
+		qt.Insert(&Object{x: -20 + 10*i, y: -20 + 10*i, r: 1})
+	}
+
+	if qt.children[0] == nil {
+		t.Error("Expected the node to split at 5 values.")
+	}
+
+	// Children smaller than MinSize are not created.
+	qt = NewNodeWithOptions(0, box, Options{MinSize: 16})
+	for i := 0; i < 100; i++ {
+		qt.Insert(randomObject(box))
+	}
+
+	if d := depth(&qt.node); d != 2 {
+		t.Error("Expected ", 2, "got", d)
+	}
+
+	var count int
+	qt.Count(&count)
+
+	if count != 100 {
+		t.Error("Expected ", 100, "got", count)
+	}
+}
+
+// wall is a long horizontal or vertical value.
+type wall struct {
+	box *Box
+}
+
+func (w *wall) Box() *Box {
+	return w.box
+}
+
+func TestLoose(t *testing.T) {
+	box := NewBox(-512, -512, 1024, 1024)
+	loose := NewNodeWithOptions(0, box, Options{Capacity: 4, Looseness: 0.5})
+	tight := NewNodeWithOptions(0, box, Options{Capacity: 4})
+
+	var walls []*wall
+	for i := 0; i < 400; i++ {
+		w := &wall{NewBox(rand.Intn(900)-500, rand.Intn(900)-500, rand.Intn(60)+1, 2)}
+		if i%2 == 1 {
+			w.box = NewBox(w.box.y, w.box.x, 2, w.box.width)
+		}
+
+		walls = append(walls, w)
+		loose.Insert(w)
+		tight.Insert(w)
+	}
+
+	// Every value below the root fits in the loose box of its node, and a
+	// value is only kept in a node with children if it does not fit in the
+	// child holding its center.
+	var check func(n *node[int, *Box, Boxer])
+	check = func(n *node[int, *Box, Boxer]) {
+		for _, v := range n.values {
+			if n.level > 0 && !n.looseBox().Contains(v.Box()) {
+				t.Error("Value", v.Box(), "does not fit in", n.looseBox())
+			}
+
+			if n.children[0] != nil {
+				for _, c := range n.children {
+					if c.boundingBox.ContainsCenter(v.Box()) && c.looseBox().Contains(v.Box()) {
+						t.Error("Value", v.Box(), "belongs in a child")
+					}
+				}
+			}
+		}
+
+		if n.children[0] != nil {
+			for _, c := range n.children {
+				check(c)
+			}
+		}
+	}
+	check(&loose.node)
+
+	for i := 0; i < 20; i++ {
+		query := NewBox(rand.Intn(1000)-500, rand.Intn(1000)-500, rand.Intn(200)+1, rand.Intn(200)+1)
+
+		expected := 0
+		for _, w := range walls {
+			if query.Intersects(w.box) {
+				expected++
+			}
+		}
+
+		for _, qt := range []*Node{loose, tight} {
+			var values []Boxer
+			qt.Retrieve(query, &values)
+
+			if len(values) != expected {
+				t.Error("Expected ", expected, "got", len(values))
+			}
+		}
+	}
+
+	for _, w := range walls {
+		if !loose.Remove(w) {
+			t.Error("Failed to remove", w.box)
+		}
+	}
+
+	var nodes int
+	loose.NodeCount(&nodes)
+
+	if nodes != 1 {
+		t.Error("Expected ", 1, "got", nodes)
+	}
+}
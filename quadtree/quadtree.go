@@ -1,14 +1,16 @@
 // The quadtree package implements a QuadTree structure that can be used for
 // any application requiring the identification of objects that are physically
 // close to one another on a Cartesian plane. The QuadTree can store any
-// object that satisfies the Boxer interface.
+// object that satisfies the Boxer interface. QuadTree[T] wraps the tree for
+// values of a single type. FloatNode and FloatQuadTree[T] are the same
+// Quadtrees with float64 coordinates.
 package quadtree
 
 import (
 	"fmt"
 )
 
-// The maximum number of values in any one node.
+// The default maximum number of values in any one node.
 const maxNodeSize = 16
 
 // Boxer is the interface for the Box method.
@@ -18,14 +20,30 @@ type Boxer interface {
 
 // Node defines a single node in the Quadtree.
 type Node struct {
+	node[int, *Box, Boxer]
+}
+
+// node is a single node in a Quadtree with boxes of type B and coordinates
+// of type C, holding values of type V. Node, FloatNode and the QuadTree
+// types are all built on it.
+type node[C coordinate, B bounds[C, B], V comparable] struct {
 	level       int
-	children    [4]*Node
-	values      []Boxer
-	boundingBox *Box
+	children    [4]*node[C, B, V]
+	values      []V
+	boundingBox B
+	options     *Options
+
+	// box returns the box of a value.
+	box func(V) B
+
+	// extent covers the bounding box and the box of every value below the
+	// node. Values are placed by their centers, so they may overhang the
+	// bounding box.
+	extent B
 }
 
 // String returns a string representing the Node structure.
-func (n *Node) String() string {
+func (n *node[C, B, V]) String() string {
 	s := fmt.Sprintf("Level: %d\nValues: %d\nChildren: %t\nBox: %s\n",
 		n.level, len(n.values), n.children[0] != nil, n.boundingBox.String())
 
@@ -40,7 +58,7 @@ func (n *Node) String() string {
 
 // Count recursively counts all of the values stored in the Quadtree. The
 // value is stored in the given int pointer.
-func (n *Node) Count(count *int) {
+func (n *node[C, B, V]) Count(count *int) {
 	*count = *count + len(n.values)
 
 	if n.children[0] != nil {
@@ -52,7 +70,7 @@ func (n *Node) Count(count *int) {
 
 // NodeCount recursively counts all of the nodes in the Quadtree. The value
 // is stored in the given int pointer.
-func (n *Node) NodeCount(count *int) {
+func (n *node[C, B, V]) NodeCount(count *int) {
 	*count = *count + 1
 
 	if n.children[0] != nil {
@@ -62,29 +80,25 @@ func (n *Node) NodeCount(count *int) {
 	}
 }
 
-// Clear recursively clears each node from the Quadtree.
-func (n *Node) Clear() {
+// Clear removes every value from the Quadtree and drops the child nodes.
+func (n *node[C, B, V]) Clear() {
 	n.values = nil
-
-	if n.children[0] != nil {
-		for i, _ := range n.children {
-			n.children[i].Clear()
-		}
-	}
+	n.children = [4]*node[C, B, V]{}
+	n.extent = n.boundingBox
 }
 
 // Split creates four new child nodes and reinserts each value so that it ends
 // up in the appropriate child node or back in the parent node.
-func (n *Node) split() {
+func (n *node[C, B, V]) split() {
 	quads := n.boundingBox.Quarter()
 
-	n.children[0] = NewNode(n.level+1, quads[0])
-	n.children[1] = NewNode(n.level+1, quads[1])
-	n.children[2] = NewNode(n.level+1, quads[2])
-	n.children[3] = NewNode(n.level+1, quads[3])
+	n.children[0] = newNode[C, B, V](n.level+1, quads[0], n.options, n.box)
+	n.children[1] = newNode[C, B, V](n.level+1, quads[1], n.options, n.box)
+	n.children[2] = newNode[C, B, V](n.level+1, quads[2], n.options, n.box)
+	n.children[3] = newNode[C, B, V](n.level+1, quads[3], n.options, n.box)
 
 	// Make a copy of our values
-	var values []Boxer
+	var values []V
 	values = append(values, n.values...)
 
 	// Clear out the current values
@@ -97,14 +111,19 @@ func (n *Node) split() {
 }
 
 // Index returns the index value of the child, if any, that contains the
-// center point of the given value.
-func (n *Node) index(v Boxer) int {
+// center point of the given box. In a loose Quadtree the box must also fit
+// in the child's loose box.
+func (n *node[C, B, V]) index(b B) int {
 	if n.children[0] == nil {
 		return -1
 	}
 
 	for i, _ := range n.children {
-		if n.children[i].boundingBox.ContainsCenter(v.Box()) {
+		if n.children[i].boundingBox.ContainsCenter(b) {
+			if n.options.Looseness > 0 && !n.children[i].looseBox().Contains(b) {
+				return -1
+			}
+
 			return i
 		}
 	}
@@ -112,21 +131,28 @@ func (n *Node) index(v Boxer) int {
 	return -1
 }
 
+// looseBox returns the node's bounding box grown on every side by the
+// Looseness option.
+func (n *node[C, B, V]) looseBox() B {
+	return n.boundingBox.loosen(n.options.Looseness)
+}
+
 // Insert adds a new value to the appropriate child node. If there are no
 // children or if the value does not fit into one of the children, the value
 // is added to this node. Split the node if it is full.
-func (n *Node) Insert(v Boxer) {
+func (n *node[C, B, V]) Insert(v V) {
 	// If this node does not contain the given box return.
-	if !n.boundingBox.ContainsCenter(v.Box()) {
+	if !n.boundingBox.ContainsCenter(n.box(v)) {
 		return
 	}
 
-	i := n.index(v)
+	n.extent = n.extent.union(n.box(v))
+	i := n.index(n.box(v))
 
 	if i == -1 {
 		n.values = append(n.values, v)
 
-		if len(n.values) > maxNodeSize {
+		if len(n.values) > n.options.Capacity && n.children[0] == nil && n.canSplit() {
 			n.split()
 		}
 	} else {
@@ -134,17 +160,132 @@ func (n *Node) Insert(v Boxer) {
 	}
 }
 
+// Remove removes the value from the Quadtree. The value is found by its
+// current box and compared with ==, so it must be the value that was
+// inserted and its dynamic type must be comparable. Remove panics for
+// values such as structs holding slices, which cannot be compared; store
+// pointers to them instead. Children are collapsed back into their parent
+// once they hold few enough values. It returns false if the value was not
+// found.
+func (n *node[C, B, V]) Remove(v V) bool {
+	return n.remove(v, n.box(v))
+}
+
+// Update moves a value that was inserted with the box oldBox to the place
+// for its current box. A value that has moved outside of the Quadtree is
+// removed. Nothing is done if the value is not found at oldBox. Values are
+// compared as in Remove.
+func (n *node[C, B, V]) Update(v V, oldBox B) {
+	if n.remove(v, oldBox) {
+		n.Insert(v)
+	}
+}
+
+// remove removes the value from the node that holds values centered in the
+// given box.
+func (n *node[C, B, V]) remove(v V, b B) bool {
+	if !n.boundingBox.ContainsCenter(b) {
+		return false
+	}
+
+	for i := range n.values {
+		if n.values[i] == v {
+			n.values = append(n.values[:i], n.values[i+1:]...)
+			n.collapse()
+			n.resize()
+
+			return true
+		}
+	}
+
+	// A center on the edge between children is in more than one of them,
+	// and a value may have been placed in any of those, for instance by a
+	// QuadTree growing around an existing node.
+	if n.children[0] == nil {
+		return false
+	}
+
+	for i := range n.children {
+		if n.children[i].remove(v, b) {
+			n.collapse()
+			n.resize()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// deepen moves the node and every node below it down a level.
+func (n *node[C, B, V]) deepen() {
+	n.level++
+
+	if n.children[0] != nil {
+		for i := range n.children {
+			n.children[i].deepen()
+		}
+	}
+}
+
+// resize recomputes the extent of the node from its values and children.
+func (n *node[C, B, V]) resize() {
+	n.extent = n.boundingBox
+
+	for i := range n.values {
+		n.extent = n.extent.union(n.box(n.values[i]))
+	}
+
+	if n.children[0] != nil {
+		for i := range n.children {
+			n.extent = n.extent.union(n.children[i].extent)
+		}
+	}
+}
+
+// collapse moves the values of the children back into the node and drops
+// the children if the children have none of their own and together with
+// the node hold no more than half of the capacity. Collapsing well below
+// the capacity stops a node near it being split and collapsed over and
+// over.
+func (n *node[C, B, V]) collapse() {
+	if n.children[0] == nil {
+		return
+	}
+
+	count := len(n.values)
+	for i := range n.children {
+		if n.children[i].children[0] != nil {
+			return
+		}
+
+		count += len(n.children[i].values)
+	}
+
+	if count > n.options.Capacity/2 {
+		return
+	}
+
+	for i := range n.children {
+		n.values = append(n.values, n.children[i].values...)
+	}
+
+	n.children = [4]*node[C, B, V]{}
+}
+
 // Retrieve returns all values that intersect with the given box. The values
-// are appended to the given Boxer slice pointer.
-func (n *Node) Retrieve(b *Box, values *[]Boxer) {
-	// If this node does not intersect with the given box return.
-	if !n.boundingBox.Intersects(b) {
+// are appended to the given slice pointer.
+func (n *node[C, B, V]) Retrieve(b B, values *[]V) {
+	// If nothing below this node intersects with the given box return. The
+	// extent is checked rather than the bounding box since values may
+	// overhang it.
+	if !n.extent.Intersects(b) {
 		return
 	}
 
 	// Find all values in this node that intersect with the given box.
 	for i, _ := range n.values {
-		if b.Intersects(n.values[i].Box()) {
+		if b.Intersects(n.box(n.values[i])) {
 			*values = append(*values, n.values[i])
 		}
 	}
@@ -157,12 +298,35 @@ func (n *Node) Retrieve(b *Box, values *[]Boxer) {
 	}
 }
 
-// NewNode creates a new Quadtree node.
+// canSplit returns true if the options allow the node to be split.
+func (n *node[C, B, V]) canSplit() bool {
+	w, h, ok := n.boundingBox.quarterSize()
+
+	return ok && n.options.canSplit(n.level, w, h)
+}
+
+// NewNode creates a new Quadtree node with the default options.
 func NewNode(level int, box *Box) *Node {
+	return NewNodeWithOptions(level, box, Options{})
+}
+
+// NewNodeWithOptions creates a new Quadtree node that splits as the options
+// say. Its children share the options.
+func NewNodeWithOptions(level int, box *Box, opts Options) *Node {
 	n := new(Node)
+	n.node = *newNode[int](level, box, opts.withDefaults(), Boxer.Box)
+
+	return n
+}
+
+func newNode[C coordinate, B bounds[C, B], V comparable](level int, box B, opts *Options, boxOf func(V) B) *node[C, B, V] {
+	n := new(node[C, B, V])
 
 	n.level = level
 	n.boundingBox = box
+	n.extent = box
+	n.options = opts
+	n.box = boxOf
 
 	return n
 }
@@ -79,3 +79,119 @@ func TestQuadTree(t *testing.T) {
 		t.Error("Expected ", 100, "got", valCount)
 	}
 }
+
+func TestRemove(t *testing.T) {
+	box := NewBox(-128, -128, 256, 256)
+	qt := NewNode(0, box)
+
+	var objects []*Object
+	for i := 0; i < 200; i++ {
+		o := randomObject(box)
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	if qt.Remove(&Object{x: 0, y: 0, r: 4}) {
+		t.Error("Removed a value that was never inserted.")
+	}
+
+	// Remove every other object and check only the rest remain.
+	for i := 0; i < len(objects); i += 2 {
+		if !qt.Remove(objects[i]) {
+			t.Error("Failed to remove", objects[i])
+		}
+	}
+
+	var count int
+	qt.Count(&count)
+
+	if count != 100 {
+		t.Error("Expected ", 100, "got", count)
+	}
+
+	var values []Boxer
+	qt.Retrieve(box, &values)
+
+	for _, v := range values {
+		for i := 0; i < len(objects); i += 2 {
+			if v == Boxer(objects[i]) {
+				t.Error("Removed value retrieved", v)
+			}
+		}
+	}
+
+	// Removing the rest collapses the tree back to a single node.
+	for i := 1; i < len(objects); i += 2 {
+		qt.Remove(objects[i])
+	}
+
+	var nodes int
+	qt.NodeCount(&nodes)
+
+	if nodes != 1 {
+		t.Error("Expected ", 1, "got", nodes)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	box := NewBox(-128, -128, 256, 256)
+	qt := NewNode(0, box)
+
+	var objects []*Object
+	for i := 0; i < 100; i++ {
+		o := randomObject(box)
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	// Move every object to the top right corner.
+	for i, o := range objects {
+		old := o.Box()
+		o.x, o.y = 100+i%20, 100+i/20
+		qt.Update(o, old)
+	}
+
+	var values []Boxer
+	qt.Retrieve(NewBox(90, 90, 40, 40), &values)
+
+	if len(values) != len(objects) {
+		t.Error("Expected ", len(objects), "got", len(values))
+	}
+
+	values = nil
+	qt.Retrieve(NewBox(-128, -128, 180, 180), &values)
+
+	if len(values) != 0 {
+		t.Error("Expected ", 0, "got", len(values))
+	}
+
+	// Moving outside the tree removes the object.
+	old := objects[0].Box()
+	objects[0].x = 1000
+	qt.Update(objects[0], old)
+
+	var count int
+	qt.Count(&count)
+
+	if count != len(objects)-1 {
+		t.Error("Expected ", len(objects)-1, "got", count)
+	}
+}
+
+func TestClear(t *testing.T) {
+	box := NewBox(-64, -64, 128, 128)
+	qt := NewNode(0, box)
+
+	for i := 0; i < 100; i++ {
+		qt.Insert(randomObject(box))
+	}
+
+	qt.Clear()
+
+	var nodes int
+	qt.NodeCount(&nodes)
+
+	if nodes != 1 {
+		t.Error("Expected ", 1, "got", nodes)
+	}
+}
@@ -0,0 +1,316 @@
+package quadtree
+
+import (
+	"math"
+)
+
+// Shape is the interface for query shapes. A Box is a Shape.
+type Shape interface {
+	// Intersects returns true if the shape overlaps the given box.
+	Intersects(b *Box) bool
+
+	// ContainsBox returns true if the given box is fully inside the shape.
+	ContainsBox(b *Box) bool
+}
+
+// FloatShape is the interface for query shapes with float64 coordinates. A
+// FloatBox is a FloatShape.
+type FloatShape interface {
+	// Intersects returns true if the shape overlaps the given box.
+	Intersects(b *FloatBox) bool
+
+	// ContainsBox returns true if the given box is fully inside the shape.
+	ContainsBox(b *FloatBox) bool
+}
+
+// shape is the interface for query shapes over boxes of type B. Shape and
+// FloatShape satisfy it.
+type shape[B any] interface {
+	Intersects(b B) bool
+	ContainsBox(b B) bool
+}
+
+// ContainsBox returns true if the given box is fully contained by this box.
+// It is the same as Contains and lets a Box be used as a Shape.
+func (b *Box) ContainsBox(c *Box) bool {
+	return b.Contains(c)
+}
+
+// Circle is a Shape defined by the (x,y) value of its center and its radius.
+type Circle struct {
+	circle[int, *Box]
+}
+
+// FloatCircle is a FloatShape defined by the (x,y) value of its center and
+// its radius.
+type FloatCircle struct {
+	circle[float64, *FloatBox]
+}
+
+type circle[C coordinate, B bounds[C, B]] struct {
+	x C
+	y C
+	r C
+}
+
+// Intersects returns true if the given box overlaps the circle.
+func (c *circle[C, B]) Intersects(b B) bool {
+	return b.distance(c.x, c.y) < float64(c.r)
+}
+
+// ContainsBox returns true if the given box is fully inside the circle.
+func (c *circle[C, B]) ContainsBox(b B) bool {
+	dx := max(c.x-b.Left(), b.Right()-c.x)
+	dy := max(c.y-b.Bottom(), b.Top()-c.y)
+
+	return math.Hypot(float64(dx), float64(dy)) <= float64(c.r)
+}
+
+// NewCircle creates a new Circle structure.
+func NewCircle(x, y, r int) *Circle {
+	c := new(Circle)
+
+	c.x = x
+	c.y = y
+	c.r = r
+
+	return c
+}
+
+// NewFloatCircle creates a new FloatCircle structure.
+func NewFloatCircle(x, y, r float64) *FloatCircle {
+	c := new(FloatCircle)
+
+	c.x = x
+	c.y = y
+	c.r = r
+
+	return c
+}
+
+// Point is a vertex of a Polygon.
+type Point struct {
+	X int
+	Y int
+}
+
+// FloatPoint is a vertex of a FloatPolygon.
+type FloatPoint struct {
+	X float64
+	Y float64
+}
+
+// Polygon is a Shape defined by its vertices in order. The last vertex is
+// joined to the first. It may be convex or concave but its edges must not
+// cross.
+type Polygon struct {
+	polygon[int, *Box]
+}
+
+// FloatPolygon is a FloatShape defined by its vertices in order, as a
+// Polygon is.
+type FloatPolygon struct {
+	polygon[float64, *FloatBox]
+}
+
+type polygon[C coordinate, B bounds[C, B]] struct {
+	points []vertex[C]
+	bounds B
+}
+
+// vertex is a vertex of a polygon.
+type vertex[C coordinate] struct {
+	x C
+	y C
+}
+
+// Intersects returns true if the given box overlaps the polygon.
+func (p *polygon[C, B]) Intersects(b B) bool {
+	if !p.bounds.Intersects(b) && !b.Contains(p.bounds) {
+		return false
+	}
+
+	// Either an edge passes through the box, the box is inside the polygon
+	// or the polygon is inside the box.
+	if p.crosses(b) || p.containsCenter(b) {
+		return true
+	}
+
+	x, y := p.points[0].x, p.points[0].y
+
+	return b.Left() < x && x < b.Right() && b.Bottom() < y && y < b.Top()
+}
+
+// ContainsBox returns true if the given box is fully inside the polygon.
+func (p *polygon[C, B]) ContainsBox(b B) bool {
+	// Without an edge passing through it the box is either all inside or
+	// all outside the polygon.
+	return p.bounds.Contains(b) && !p.crosses(b) && p.containsCenter(b)
+}
+
+// crosses returns true if an edge of the polygon passes through the inside
+// of the box.
+func (p *polygon[C, B]) crosses(b B) bool {
+	for i := range p.points {
+		if segmentCrosses(p.points[i], p.points[(i+1)%len(p.points)], b) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// containsCenter returns true if the center of the box is inside the
+// polygon, counting the edges a ray from the center crosses.
+func (p *polygon[C, B]) containsCenter(b B) bool {
+	x := (float64(b.Left()) + float64(b.Right())) / 2
+	y := (float64(b.Bottom()) + float64(b.Top())) / 2
+
+	inside := false
+	for i := range p.points {
+		a, c := p.points[i], p.points[(i+1)%len(p.points)]
+
+		if (float64(a.y) > y) != (float64(c.y) > y) {
+			cross := float64(a.x) + (y-float64(a.y))*float64(c.x-a.x)/float64(c.y-a.y)
+			if x < cross {
+				inside = !inside
+			}
+		}
+	}
+
+	return inside
+}
+
+// segmentCrosses returns true if the segment from a to c passes through the
+// inside of the box, not just along or touching its edges.
+func segmentCrosses[C coordinate, B bounds[C, B]](a, c vertex[C], b B) bool {
+	// Clip the segment to the box, keeping the range of t for which
+	// a + t(c - a) is within it.
+	t0, t1 := 0.0, 1.0
+	dx, dy := float64(c.x-a.x), float64(c.y-a.y)
+
+	clip := func(q, r float64) bool {
+		if q == 0 {
+			return r >= 0
+		}
+
+		t := r / q
+		if q < 0 {
+			t0 = math.Max(t0, t)
+		} else {
+			t1 = math.Min(t1, t)
+		}
+
+		return t0 <= t1
+	}
+
+	if !clip(-dx, float64(a.x-b.Left())) || !clip(dx, float64(b.Right()-a.x)) ||
+		!clip(-dy, float64(a.y-b.Bottom())) || !clip(dy, float64(b.Top()-a.y)) {
+		return false
+	}
+
+	// The middle of the clipped segment is inside the box unless all of it
+	// is on an edge.
+	t := (t0 + t1) / 2
+	x, y := float64(a.x)+t*dx, float64(a.y)+t*dy
+
+	return float64(b.Left()) < x && x < float64(b.Right()) &&
+		float64(b.Bottom()) < y && y < float64(b.Top())
+}
+
+// NewPolygon creates a new Polygon structure from its vertices. It returns
+// nil if there are fewer than three.
+func NewPolygon(points []Point) *Polygon {
+	if len(points) < 3 {
+		return nil
+	}
+
+	p := new(Polygon)
+	p.bounds = NewBox(points[0].X, points[0].Y, 0, 0)
+
+	for _, pt := range points {
+		p.points = append(p.points, vertex[int]{pt.X, pt.Y})
+		p.bounds = p.bounds.union(NewBox(pt.X, pt.Y, 0, 0))
+	}
+
+	return p
+}
+
+// NewFloatPolygon creates a new FloatPolygon structure from its vertices. It
+// returns nil if there are fewer than three.
+func NewFloatPolygon(points []FloatPoint) *FloatPolygon {
+	if len(points) < 3 {
+		return nil
+	}
+
+	p := new(FloatPolygon)
+	p.bounds = NewFloatBox(points[0].X, points[0].Y, 0, 0)
+
+	for _, pt := range points {
+		p.points = append(p.points, vertex[float64]{pt.X, pt.Y})
+		p.bounds = p.bounds.union(NewFloatBox(pt.X, pt.Y, 0, 0))
+	}
+
+	return p
+}
+
+// Search returns all values whose boxes intersect the shape. Nodes that do
+// not intersect the shape are skipped, and the values with area below nodes
+// inside the shape are taken without being tested. The values are appended
+// to the given slice pointer.
+func (n *node[C, B, V]) Search(s shape[B], values *[]V) {
+	n.SearchFunc(s, nil, values)
+}
+
+// SearchFunc returns all values whose boxes intersect the shape and for
+// which filter returns true. A nil filter accepts every value. The values
+// are appended to the given slice pointer.
+func (n *node[C, B, V]) SearchFunc(s shape[B], filter func(V) bool, values *[]V) {
+	if !s.Intersects(n.extent) {
+		return
+	}
+
+	if s.ContainsBox(n.extent) {
+		n.collect(s, filter, values)
+		return
+	}
+
+	for i := range n.values {
+		if s.Intersects(n.box(n.values[i])) && (filter == nil || filter(n.values[i])) {
+			*values = append(*values, n.values[i])
+		}
+	}
+
+	if n.children[0] != nil {
+		for i := range n.children {
+			n.children[i].SearchFunc(s, filter, values)
+		}
+	}
+}
+
+// collect appends every value below a node inside the shape for which
+// filter returns true.
+func (n *node[C, B, V]) collect(s shape[B], filter func(V) bool, values *[]V) {
+	for i := range n.values {
+		if inside(s, n.box(n.values[i])) && (filter == nil || filter(n.values[i])) {
+			*values = append(*values, n.values[i])
+		}
+	}
+
+	if n.children[0] != nil {
+		for i := range n.children {
+			n.children[i].collect(s, filter, values)
+		}
+	}
+}
+
+// inside returns true if a box contained by the shape intersects it. Every
+// box with area does, but a line or point on the edge of the shape is
+// contained without intersecting it, so those are tested.
+func inside[C coordinate, B bounds[C, B]](s shape[B], b B) bool {
+	if b.Left() < b.Right() && b.Bottom() < b.Top() {
+		return true
+	}
+
+	return s.Intersects(b)
+}
@@ -0,0 +1,189 @@
+package quadtree
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// An L shaped polygon covering (0, 0), (20, 10) and (0, 10), (10, 20).
+var ell = NewPolygon([]Point{{0, 0}, {20, 0}, {20, 10}, {10, 10}, {10, 20}, {0, 20}})
+
+func TestCircle(t *testing.T) {
+	c := NewCircle(0, 0, 10)
+
+	tests := []struct {
+		box        *Box
+		intersects bool
+		contains   bool
+	}{
+		{NewBox(-2, -2, 4, 4), true, true},
+		{NewBox(5, 5, 2, 2), true, true},
+		{NewBox(6, 6, 4, 4), true, false},
+		{NewBox(8, 8, 4, 4), false, false},
+		{NewBox(-20, -1, 40, 2), true, false},
+		{NewBox(-20, -20, 40, 40), true, false},
+	}
+
+	for _, test := range tests {
+		if c.Intersects(test.box) != test.intersects {
+			t.Error("Expected ", test.intersects, "got", !test.intersects, "for", test.box)
+		}
+
+		if c.ContainsBox(test.box) != test.contains {
+			t.Error("Expected ", test.contains, "got", !test.contains, "for", test.box)
+		}
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	if NewPolygon([]Point{{0, 0}, {1, 1}}) != nil {
+		t.Error("Expected no polygon from two points.")
+	}
+
+	tests := []struct {
+		box        *Box
+		intersects bool
+		contains   bool
+	}{
+		{NewBox(2, 2, 4, 4), true, true},
+		{NewBox(12, 2, 6, 6), true, true},
+		{NewBox(0, 0, 20, 10), true, true},
+		{NewBox(12, 12, 6, 6), false, false}, // In the notch.
+		{NewBox(8, 8, 4, 4), true, false},    // Across the inner corner.
+		{NewBox(10, 10, 10, 10), false, false},
+		{NewBox(-10, -10, 40, 40), true, false}, // Around the polygon.
+		{NewBox(25, 5, 5, 5), false, false},
+		{NewBox(18, 5, 5, 2), true, false},
+	}
+
+	for _, test := range tests {
+		if ell.Intersects(test.box) != test.intersects {
+			t.Error("Expected ", test.intersects, "got", !test.intersects, "for", test.box)
+		}
+
+		if ell.ContainsBox(test.box) != test.contains {
+			t.Error("Expected ", test.contains, "got", !test.contains, "for", test.box)
+		}
+	}
+}
+
+func TestFloatShapes(t *testing.T) {
+	c := NewFloatCircle(0.5, 0.5, 0.25)
+	p := NewFloatPolygon([]FloatPoint{{0, 0}, {1, 0}, {0, 1}})
+
+	if NewFloatPolygon([]FloatPoint{{0, 0}, {1, 1}}) != nil {
+		t.Error("Expected no polygon from two points.")
+	}
+
+	tests := []struct {
+		shape      FloatShape
+		box        *FloatBox
+		intersects bool
+		contains   bool
+	}{
+		{c, NewFloatBox(0.45, 0.45, 0.1, 0.1), true, true},
+		{c, NewFloatBox(0.6, 0.6, 0.3, 0.3), true, false},
+		{c, NewFloatBox(0.7, 0.7, 0.3, 0.3), false, false},
+		{p, NewFloatBox(0.1, 0.1, 0.2, 0.2), true, true},
+		{p, NewFloatBox(0.4, 0.4, 0.2, 0.2), true, false},
+		{p, NewFloatBox(0.6, 0.6, 0.2, 0.2), false, false},
+		{p, NewFloatBox(-1, -1, 3, 3), true, false},
+	}
+
+	for _, test := range tests {
+		if test.shape.Intersects(test.box) != test.intersects {
+			t.Error("Expected ", test.intersects, "got", !test.intersects, "for", test.box)
+		}
+
+		if test.shape.ContainsBox(test.box) != test.contains {
+			t.Error("Expected ", test.contains, "got", !test.contains, "for", test.box)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	box := NewBox(-64, -64, 128, 128)
+	qt := NewNode(0, box)
+
+	var objects []*Object
+	for i := 0; i < 500; i++ {
+		o := randomObject(box)
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	star := NewPolygon([]Point{{0, 40}, {10, 10}, {40, 0}, {10, -10}, {0, -40}, {-10, -10}, {-40, 0}, {-10, 10}})
+
+	for _, s := range []Shape{NewCircle(10, -5, 20), ell, star, NewBox(-30, -30, 20, 50)} {
+		var expected []*Object
+		for _, o := range objects {
+			if s.Intersects(o.Box()) {
+				expected = append(expected, o)
+			}
+		}
+
+		var values []Boxer
+		qt.Search(s, &values)
+
+		if len(values) != len(expected) {
+			t.Error("Expected ", len(expected), "got", len(values))
+		}
+
+		// Only the values the filter accepts are returned.
+		values = nil
+		qt.SearchFunc(s, func(v Boxer) bool { return v.(*Object).x > 0 }, &values)
+
+		count := 0
+		for _, o := range expected {
+			if o.x > 0 {
+				count++
+			}
+		}
+
+		if len(values) != count {
+			t.Error("Expected ", count, "got", len(values))
+		}
+	}
+}
+
+func TestSearchEdges(t *testing.T) {
+	box := NewBox(0, 0, 64, 64)
+	qt := NewNodeWithOptions(0, box, Options{Capacity: 2})
+	tree := NewQuadTreeWithOptions[*plain](box, Options{Capacity: 2})
+
+	// Points and lines on the edges of the queries below, along with values
+	// with area so the tree splits.
+	boxes := []*Box{
+		NewBox(0, 5, 0, 0), NewBox(64, 10, 0, 0), NewBox(10, 0, 0, 0), NewBox(10, 64, 0, 0),
+		NewBox(0, 0, 0, 0), NewBox(32, 32, 0, 0), NewBox(32, 40, 0, 0), NewBox(20, 16, 0, 0),
+		NewBox(0, 20, 0, 8), NewBox(40, 64, 8, 0), NewBox(16, 30, 4, 4), NewBox(50, 50, 4, 4),
+	}
+
+	for i := 0; i < 20; i++ {
+		boxes = append(boxes, NewBox(rand.Intn(60), rand.Intn(60), rand.Intn(4)+1, rand.Intn(4)+1))
+	}
+
+	for _, b := range boxes {
+		qt.Insert(&plain{b})
+		tree.Insert(&plain{b})
+	}
+
+	for _, query := range []*Box{box, NewBox(0, 0, 32, 32), NewBox(32, 0, 32, 64), NewBox(10, 5, 22, 27)} {
+		var retrieved, searched []Boxer
+		qt.Retrieve(query, &retrieved)
+		qt.Search(query, &searched)
+
+		if len(searched) != len(retrieved) {
+			t.Error("Expected ", len(retrieved), "got", len(searched), "for", query)
+		}
+
+		count := 0
+		for range tree.Search(query) {
+			count++
+		}
+
+		if count != len(retrieved) || len(tree.Query(query)) != len(retrieved) {
+			t.Error("Expected ", len(retrieved), "got", count, "for", query)
+		}
+	}
+}
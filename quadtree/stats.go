@@ -0,0 +1,108 @@
+package quadtree
+
+import (
+	"iter"
+)
+
+// Stats describes the shape of a QuadTree. The slices are indexed by level,
+// with the root at level 0.
+type Stats struct {
+	Nodes       int
+	Values      int
+	Depth       int
+	EmptyLeaves int
+
+	// NodesPerLevel holds the number of nodes at each level.
+	NodesPerLevel []int
+
+	// ValuesPerLevel holds the number of values held by the nodes at each
+	// level.
+	ValuesPerLevel []int
+}
+
+// Stats walks the QuadTree and returns a report of its shape.
+func (q *tree[C, B, T]) Stats() Stats {
+	var s Stats
+	q.root.stats(0, &s)
+
+	return s
+}
+
+func (n *node[C, B, V]) stats(level int, s *Stats) {
+	if level >= len(s.NodesPerLevel) {
+		s.NodesPerLevel = append(s.NodesPerLevel, 0)
+		s.ValuesPerLevel = append(s.ValuesPerLevel, 0)
+	}
+
+	s.Nodes++
+	s.Values += len(n.values)
+	s.Depth = max(s.Depth, level)
+	s.NodesPerLevel[level]++
+	s.ValuesPerLevel[level] += len(n.values)
+
+	if n.children[0] == nil {
+		if len(n.values) == 0 {
+			s.EmptyLeaves++
+		}
+
+		return
+	}
+
+	for i := range n.children {
+		n.children[i].stats(level+1, s)
+	}
+}
+
+// All returns an iterator over every value in the QuadTree. The QuadTree
+// must not be changed while it is in use.
+func (q *tree[C, B, T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		q.root.walk(nil, false, yield)
+	}
+}
+
+// Search returns an iterator over the values whose boxes intersect the
+// shape. Stopping the iteration early stops the search, so no more of the
+// tree is walked than is needed. The QuadTree must not be changed while it
+// is in use.
+func (q *tree[C, B, T]) Search(s shape[B]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		q.root.walk(s, false, yield)
+	}
+}
+
+// walk calls yield for each value below the node whose box intersects the
+// shape, or for every value if the shape is nil. Within is true below a
+// node inside the shape. It returns false as soon as yield does.
+func (n *node[C, B, V]) walk(s shape[B], within bool, yield func(V) bool) bool {
+	if s != nil && !within {
+		if !s.Intersects(n.extent) {
+			return true
+		}
+
+		within = s.ContainsBox(n.extent)
+	}
+
+	for i := range n.values {
+		match := s == nil
+		if !match && within {
+			match = inside(s, n.box(n.values[i]))
+		} else if !match {
+			match = s.Intersects(n.box(n.values[i]))
+		}
+
+		if match && !yield(n.values[i]) {
+			return false
+		}
+	}
+
+	if n.children[0] != nil {
+		for i := range n.children {
+			if !n.children[i].walk(s, within, yield) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,110 @@
+package quadtree
+
+import (
+	"testing"
+)
+
+func TestQuadTreeStats(t *testing.T) {
+	box := NewBox(-128, -128, 256, 256)
+	qt := NewQuadTreeWithOptions[*Object](box, Options{Capacity: 4})
+
+	var objects []*Object
+	for i := 0; i < 200; i++ {
+		o := randomObject(box)
+		objects = append(objects, o)
+		qt.Insert(o)
+	}
+
+	qt.Insert(&Object{x: 1000, y: 0, r: 1})
+
+	if qt.Len() != len(objects) {
+		t.Error("Expected ", len(objects), "got", qt.Len())
+	}
+
+	s := qt.Stats()
+
+	var nodes, count int
+	qt.root.NodeCount(&nodes)
+	qt.root.Count(&count)
+
+	if s.Nodes != nodes || s.Values != count || s.Depth != depth(qt.root) || qt.Depth() != s.Depth {
+		t.Error("Stats do not match the tree:", s)
+	}
+
+	if len(s.NodesPerLevel) != s.Depth+1 || s.NodesPerLevel[0] != 1 {
+		t.Error("Expected one level per depth with one root, got", s.NodesPerLevel)
+	}
+
+	total := 0
+	for _, v := range s.ValuesPerLevel {
+		total += v
+	}
+
+	if total != count {
+		t.Error("Expected ", count, "got", total)
+	}
+
+	if b := qt.Bounds(); b.Left() != -128 || b.Right() != 128 {
+		t.Error("Expected ", box, "got", b)
+	}
+
+	// Moving a value out of the tree removes it.
+	old := objects[0].Box()
+	objects[0].x = 1000
+	qt.Update(objects[0], old)
+	qt.Remove(objects[1])
+
+	if qt.Len() != len(objects)-2 {
+		t.Error("Expected ", len(objects)-2, "got", qt.Len())
+	}
+
+	qt.Clear()
+	if qt.Len() != 0 || qt.Stats().Nodes != 1 {
+		t.Error("Expected an empty tree after clearing.")
+	}
+}
+
+func TestQuadTreeIterators(t *testing.T) {
+	box := NewBox(-128, -128, 256, 256)
+	qt := NewQuadTree[*Object](box)
+
+	for i := 0; i < 200; i++ {
+		qt.Insert(randomObject(box))
+	}
+
+	count := 0
+	for range qt.All() {
+		count++
+	}
+
+	if count != qt.Len() {
+		t.Error("Expected ", qt.Len(), "got", count)
+	}
+
+	circle := NewCircle(0, 0, 60)
+
+	count = 0
+	for o := range qt.Search(circle) {
+		if !circle.Intersects(o.Box()) {
+			t.Error("Value outside of the shape", o)
+		}
+		count++
+	}
+
+	if count != len(qt.Query(circle)) {
+		t.Error("Expected ", len(qt.Query(circle)), "got", count)
+	}
+
+	// Stopping early yields no more values.
+	count = 0
+	for range qt.Search(box) {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+
+	if count != 3 {
+		t.Error("Expected ", 3, "got", count)
+	}
+}
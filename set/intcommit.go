@@ -0,0 +1,137 @@
+package set
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/asggo/structures/merkle"
+)
+
+// IntCommitment is a Merkle commitment to the sorted members of an
+// IntSet. Clients holding only the digest can check membership answers
+// using the proofs it produces.
+type IntCommitment struct {
+	members []int
+	tree    *merkle.Merkle
+}
+
+// IntLeaf is a committed member along with the proof of its position.
+type IntLeaf struct {
+	Member int           `json:"member"`
+	Proof  *merkle.Proof `json:"proof"`
+}
+
+// IntProof proves that a value is or is not a member of a committed set.
+// A member is proven by its own leaf, held in Lower. A non-member is proven
+// by the adjacent leaves that sort either side of it; Lower is nil when the
+// value sorts before every member and Upper is nil when it sorts after every
+// member. Both are nil when the set is empty.
+type IntProof struct {
+	Value   int      `json:"value"`
+	Present bool     `json:"present"`
+	Lower   *IntLeaf `json:"lower,omitempty"`
+	Upper   *IntLeaf `json:"upper,omitempty"`
+}
+
+// Digest returns the hex encoded digest of the commitment. The commitment to
+// an empty set has merkle.EmptyDigest.
+func (c *IntCommitment) Digest() string {
+	return c.tree.Digest()
+}
+
+// Size returns the number of committed members.
+func (c *IntCommitment) Size() int {
+	return len(c.members)
+}
+
+// Prove returns a proof that the value is or is not a member of the
+// committed set. Absence from an empty set is proven by a proof with no
+// leaves.
+func (c *IntCommitment) Prove(v int) *IntProof {
+	p := &IntProof{Value: v}
+	i := sort.SearchInts(c.members, v)
+
+	if i < len(c.members) && c.members[i] == v {
+		p.Present = true
+		p.Lower = c.leaf(i)
+
+		return p
+	}
+
+	if i > 0 {
+		p.Lower = c.leaf(i - 1)
+	}
+
+	if i < len(c.members) {
+		p.Upper = c.leaf(i)
+	}
+
+	return p
+}
+
+func (c *IntCommitment) leaf(i int) *IntLeaf {
+	return &IntLeaf{Member: c.members[i], Proof: c.tree.Proof(i)}
+}
+
+// Verify returns true if the proof is valid for the commitment with the
+// given digest.
+func (p *IntProof) Verify(digest string) bool {
+	if p.Present {
+		return p.Lower != nil && p.Upper == nil &&
+			p.Lower.Member == p.Value && p.Lower.verify(digest)
+	}
+
+	if p.Lower == nil && p.Upper == nil {
+		return digest == merkle.EmptyDigest
+	}
+
+	if p.Lower != nil && !(p.Lower.Member < p.Value && p.Lower.verify(digest)) {
+		return false
+	}
+
+	if p.Upper != nil && !(p.Upper.Member > p.Value && p.Upper.verify(digest)) {
+		return false
+	}
+
+	// The leaves must be adjacent, or the only leaf must be at the edge of
+	// the tree.
+	switch {
+	case p.Lower == nil:
+		return p.Upper.Proof.Index == 0
+	case p.Upper == nil:
+		return p.Lower.Proof.Index == p.Lower.Proof.Size-1
+	default:
+		return p.Upper.Proof.Index == p.Lower.Proof.Index+1 &&
+			p.Upper.Proof.Size == p.Lower.Proof.Size
+	}
+}
+
+func (l *IntLeaf) verify(digest string) bool {
+	return l.Proof != nil && l.Proof.Verify(intBlock(l.Member), digest)
+}
+
+// intBlock returns the block committed for a member, its eight byte big
+// endian encoding. No block is the same length as an interior node, so a
+// member cannot be passed off as a subtree.
+func intBlock(m int) []byte {
+	block := make([]byte, 8)
+	binary.BigEndian.PutUint64(block, uint64(m))
+
+	return block
+}
+
+// Commit returns a Merkle commitment to the current members of the set.
+// Later changes to the set do not affect the commitment.
+func (s *IntSet) Commit() *IntCommitment {
+	c := new(IntCommitment)
+	c.members = s.Members()
+
+	var blocks [][]byte
+	for _, m := range c.members {
+		blocks = append(blocks, intBlock(m))
+	}
+
+	c.tree = merkle.NewMerkle(blocks)
+
+	return c
+}
@@ -0,0 +1,37 @@
+package set
+
+import (
+	"testing"
+)
+
+func TestIntCommitment(t *testing.T) {
+	s := NewIntSet([]int{-20, 4, 8, 15, 16, 23, 42})
+	c := s.Commit()
+	digest := c.Digest()
+
+	for _, m := range s.Members() {
+		p := c.Prove(m)
+		if !p.Present || !p.Verify(digest) {
+			t.Errorf("Membership of %d did not verify.", m)
+		}
+	}
+
+	for _, v := range []int{-100, 0, 9, 41, 100} {
+		p := c.Prove(v)
+		if p.Present || !p.Verify(digest) {
+			t.Errorf("Absence of %d did not verify.", v)
+		}
+	}
+
+	p := c.Prove(9)
+	p.Value = 15
+	if p.Verify(digest) {
+		t.Error("Absence proof verified for a member.")
+	}
+
+	p = c.Prove(9)
+	p.Lower = c.Prove(4).Lower
+	if p.Verify(digest) {
+		t.Error("Absence proof verified with a gap between leaves.")
+	}
+}
@@ -0,0 +1,78 @@
+package set
+
+import (
+	"testing"
+
+	"github.com/asggo/structures/merkle"
+)
+
+func TestStringCommitment(t *testing.T) {
+	s := NewStringSet([]string{"bob", "dave", "alice", "carol", "erin"})
+	c := s.Commit()
+	digest := c.Digest()
+
+	// Later changes to the set do not change the commitment.
+	s.Add("frank")
+	if c.Size() != 5 || s.Commit().Digest() == digest {
+		t.Error("Commitment changed with the set.")
+	}
+
+	for _, m := range []string{"alice", "carol", "erin"} {
+		p := c.Prove(m)
+		if !p.Present || !p.Verify(digest) {
+			t.Errorf("Membership of %s did not verify.", m)
+		}
+	}
+
+	// Before the first member, between members and after the last member.
+	for _, v := range []string{"aaron", "bridget", "zoe"} {
+		p := c.Prove(v)
+		if p.Present || !p.Verify(digest) {
+			t.Errorf("Absence of %s did not verify.", v)
+		}
+	}
+
+	// A proof must not be usable for another value.
+	p := c.Prove("bridget")
+	p.Value = "carol"
+	if p.Verify(digest) {
+		t.Error("Absence proof verified for a member.")
+	}
+
+	p = c.Prove("carol")
+	p.Value = "carl"
+	if p.Verify(digest) {
+		t.Error("Membership proof verified for another value.")
+	}
+
+	// Leaves that are not adjacent do not prove absence.
+	p = c.Prove("bridget")
+	p.Upper = c.Prove("dave").Lower
+	if p.Verify(digest) {
+		t.Error("Absence proof verified with a gap between leaves.")
+	}
+
+	p = c.Prove("zoe")
+	p.Lower = c.Prove("dave").Lower
+	if p.Verify(digest) {
+		t.Error("Absence proof verified without the last leaf.")
+	}
+
+	if c.Prove("alice").Verify(NewStringSet([]string{"alice"}).Commit().Digest()) {
+		t.Error("Proof verified against another commitment.")
+	}
+
+	empty := NewStringSet([]string{}).Commit()
+	if empty.Digest() != merkle.EmptyDigest {
+		t.Error("Expected ", merkle.EmptyDigest, "got", empty.Digest())
+	}
+
+	p = empty.Prove("alice")
+	if p.Present || !p.Verify(empty.Digest()) {
+		t.Error("Absence from an empty set did not verify.")
+	}
+
+	if p.Verify(digest) {
+		t.Error("Empty set proof verified against a non-empty commitment.")
+	}
+}